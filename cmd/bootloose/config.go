@@ -17,6 +17,9 @@ func NewConfigCommand() *cobra.Command {
 	cmd.AddCommand(
 		NewConfigCreateCommand(),
 		NewConfigGetCommand(),
+		NewConfigSetCommand(),
+		NewConfigUnsetCommand(),
+		NewConfigPresetsCommand(),
 	)
 
 	return cmd
@@ -5,9 +5,11 @@
 package bootloose
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/k0sproject/bootloose/pkg/cluster"
@@ -17,43 +19,62 @@ import (
 )
 
 type configCreateOptions struct {
-	override bool
-	config   config.Config
-	volumes  []string
+	override     bool
+	preset       string
+	presetParams []string
+	name         string
+	key          string
+	networks     []string
+	replicas     int
+	image        string
+	privileged   bool
+	cmd          string
+	volumes      []string
+	machines     []string
+	ports        []string
+	env          []string
+	extraArgs    []string
 }
 
 func NewConfigCreateCommand() *cobra.Command {
-	opts := &configCreateOptions{config: config.DefaultConfig()}
+	defaults := config.DefaultConfig()
+	opts := &configCreateOptions{}
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a cluster configuration",
-		RunE:  opts.create,
+		Long: `Create a cluster configuration, either from the default single machine template
+	or from a built-in preset given with --preset, see 'config presets'. Preset
+	parameters are given with --param NAME=VALUE.
+	Machine templates can be described with the repeatable --machine flag, as a comma
+	separated list of key=value pairs. Valid keys are name, count, image, privileged,
+	port and network, the last two can be repeated:
+	  --machine name=controller%d,count=3,port=6443:6443 --machine name=worker%d,count=2
+	Templates with the same name as a template of the preset are updated, others are
+	added. Without --preset, they replace the default template.
+	The --image, --networks, --privileged, --cmd, --volume, --port, --env and --extra-arg
+	flags apply to every machine template, --networks failing for the templates that
+	already have networks.`,
+		Args: cobra.NoArgs,
+		RunE: opts.create,
 	}
 
 	cmd.Flags().BoolVar(&opts.override, "override", false, "Override configuration file if it exists")
-
-	name := &opts.config.Cluster.Name
-	cmd.Flags().StringVarP(name, "name", "n", *name, "Name of the cluster")
-
-	private := &opts.config.Cluster.PrivateKey
-	cmd.Flags().StringVarP(private, "key", "k", *private, "Name of the private and public key files")
-
-	networks := &opts.config.Machines[0].Spec.Networks
-	cmd.Flags().StringSliceVar(networks, "networks", *networks, "Networks names the machines are assigned to")
-
-	replicas := &opts.config.Machines[0].Count
-	cmd.Flags().IntVarP(replicas, "replicas", "r", *replicas, "Number of machine replicas")
-
-	image := &opts.config.Machines[0].Spec.Image
-	cmd.Flags().StringVarP(image, "image", "i", *image, "Docker image to use in the containers")
-
-	privileged := &opts.config.Machines[0].Spec.Privileged
-	cmd.Flags().BoolVar(privileged, "privileged", *privileged, "Create privileged containers")
-
-	containerCmd := &opts.config.Machines[0].Spec.Cmd
-	cmd.Flags().StringVarP(containerCmd, "cmd", "d", *containerCmd, "The command to execute on the container")
-
+	cmd.Flags().StringVar(&opts.preset, "preset", "", "Name of the built-in preset to start from")
+	cmd.Flags().StringVar(&opts.preset, "from-template", "", "Alias of --preset")
+	cmd.Flags().StringArrayVar(&opts.presetParams, "param", nil, "Preset parameter in the form NAME=VALUE")
+	cmd.Flags().StringVarP(&opts.name, "name", "n", defaults.Cluster.Name, "Name of the cluster")
+	cmd.Flags().StringVarP(&opts.key, "key", "k", defaults.Cluster.PrivateKey, "Name of the private and public key files")
+	cmd.Flags().StringSliceVar(&opts.networks, "networks", nil, "Networks names the machines are assigned to")
+	cmd.Flags().IntVarP(&opts.replicas, "replicas", "r", defaults.Machines[0].Count, "Number of machine replicas")
+	cmd.Flags().StringVarP(&opts.image, "image", "i", defaults.Machines[0].Spec.Image, "Docker image to use in the containers")
+	cmd.Flags().BoolVar(&opts.privileged, "privileged", false, "Create privileged containers")
+	cmd.Flags().StringVarP(&opts.cmd, "cmd", "d", "", "The command to execute on the container")
 	cmd.Flags().StringSliceVarP(&opts.volumes, "volume", "v", nil, "Volumes to mount in the container")
+	cmd.Flags().StringArrayVarP(&opts.machines, "machine", "m", nil, "Machine template, eg. name=worker%d,count=2,image=IMAGE,port=80:8080/tcp,network=net1")
+	cmd.Flags().StringSliceVarP(&opts.ports, "port", "p", nil, "Port mappings in the form [[address:]hostPort:]containerPort[/protocol]")
+	cmd.Flags().StringArrayVarP(&opts.env, "env", "e", nil, "Environment variables in the form NAME=VALUE")
+	cmd.Flags().StringArrayVar(&opts.extraArgs, "extra-arg", nil, "Extra argument passed to docker when creating the containers")
+	cmd.MarkFlagsMutuallyExclusive("preset", "from-template")
 
 	return cmd
 }
@@ -69,7 +90,11 @@ func configExists(path string) bool {
 }
 
 func (opts *configCreateOptions) create(cmd *cobra.Command, args []string) error {
-	cluster, err := cluster.New(opts.config)
+	conf, err := opts.buildConfig(cmd)
+	if err != nil {
+		return err
+	}
+	cluster, err := cluster.New(conf)
 	if err != nil {
 		return err
 	}
@@ -77,16 +102,246 @@ func (opts *configCreateOptions) create(cmd *cobra.Command, args []string) error
 	if configExists(cfgFile) && !opts.override {
 		return fmt.Errorf("configuration file at %s already exists", cfgFile)
 	}
+	return cluster.Save(cfgFile)
+}
+
+// buildConfig renders the configuration described by the command line flags.
+func (opts *configCreateOptions) buildConfig(cmd *cobra.Command) (config.Config, error) {
+	flags := cmd.Flags()
+	conf := config.DefaultConfig()
+	if opts.preset != "" {
+		preset, err := config.PresetByName(opts.preset)
+		if err != nil {
+			return conf, err
+		}
+		params := map[string]string{}
+		for _, p := range opts.presetParams {
+			name, value, ok := strings.Cut(p, "=")
+			if !ok || name == "" {
+				return conf, fmt.Errorf("invalid preset parameter %q, expected NAME=VALUE", p)
+			}
+			params[name] = value
+		}
+		if conf, err = preset.Config(params); err != nil {
+			return conf, err
+		}
+	} else if len(opts.presetParams) > 0 {
+		return conf, errors.New("--param can only be used with --preset")
+	}
+
+	if flags.Changed("name") || opts.preset == "" {
+		conf.Cluster.Name = opts.name
+	}
+	if flags.Changed("key") || opts.preset == "" {
+		conf.Cluster.PrivateKey = opts.key
+	}
+
+	if len(opts.machines) > 0 && opts.preset == "" {
+		conf.Machines = nil
+	}
+	for _, m := range opts.machines {
+		if err := opts.addMachine(&conf, m); err != nil {
+			return conf, err
+		}
+	}
+
+	if flags.Changed("replicas") {
+		if len(conf.Machines) != 1 {
+			return conf, errors.New("--replicas can only be used with a single machine template, use count= in --machine instead")
+		}
+		conf.Machines[0].Count = opts.replicas
+	}
+
+	var mappings []config.PortMapping
+	for _, p := range opts.ports {
+		mapping, err := parsePortMapping(p)
+		if err != nil {
+			return conf, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	var volumes []config.Volume
 	for _, v := range opts.volumes {
 		volume, err := parseVolume(v)
 		if err != nil {
-			return err
+			return conf, err
 		}
-		for _, machine := range opts.config.Machines {
-			machine.Spec.Volumes = append(machine.Spec.Volumes, volume)
+		volumes = append(volumes, volume)
+	}
+	env := map[string]string{}
+	for _, e := range opts.env {
+		name, value, ok := strings.Cut(e, "=")
+		if !ok || name == "" {
+			return conf, fmt.Errorf("invalid environment variable %q, expected NAME=VALUE", e)
 		}
+		env[name] = value
 	}
-	return cluster.Save(cfgFile)
+
+	for _, machine := range conf.Machines {
+		spec := machine.Spec
+		if flags.Changed("image") {
+			spec.Image = opts.image
+		}
+		if flags.Changed("networks") {
+			if len(spec.Networks) > 0 {
+				return conf, fmt.Errorf("%s: --networks can't replace the networks of the machine template, use network= in --machine instead", spec.Name)
+			}
+			spec.Networks = opts.networks
+		}
+		if opts.privileged {
+			spec.Privileged = true
+		}
+		if opts.cmd != "" {
+			spec.Cmd = opts.cmd
+		}
+		for _, mapping := range mappings {
+			spec.PortMappings = addPortMapping(spec.PortMappings, mapping)
+		}
+		spec.Volumes = append(spec.Volumes, volumes...)
+		for name, value := range env {
+			if spec.Env == nil {
+				spec.Env = map[string]string{}
+			}
+			spec.Env[name] = value
+		}
+		spec.ExtraArgs = append(spec.ExtraArgs, opts.extraArgs...)
+	}
+	return conf, nil
+}
+
+// addMachine parses a --machine value and adds it to conf, or updates the
+// template with the same name.
+func (opts *configCreateOptions) addMachine(conf *config.Config, value string) error {
+	values := map[string][]string{}
+	for _, kv := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("invalid machine %q: %q is not a key=value pair", value, kv)
+		}
+		values[key] = append(values[key], val)
+	}
+	names := values["name"]
+	if len(names) != 1 {
+		return fmt.Errorf("invalid machine %q: a single name is required", value)
+	}
+
+	var machine *config.MachineReplicas
+	for i := range conf.Machines {
+		if conf.Machines[i].Spec != nil && conf.Machines[i].Spec.Name == names[0] {
+			machine = &conf.Machines[i]
+		}
+	}
+	if machine == nil {
+		conf.Machines = append(conf.Machines, config.MachineReplicas{
+			Count: 1,
+			Spec: &config.Machine{
+				Name:         names[0],
+				Image:        opts.image,
+				PortMappings: []config.PortMapping{{ContainerPort: 22}},
+			},
+		})
+		machine = &conf.Machines[len(conf.Machines)-1]
+	}
+
+	for key, vals := range values {
+		last := vals[len(vals)-1]
+		switch key {
+		case "name":
+		case "count":
+			count, err := strconv.Atoi(last)
+			if err != nil || count < 0 {
+				return fmt.Errorf("invalid machine %q: invalid count %q", value, last)
+			}
+			machine.Count = count
+		case "image":
+			machine.Spec.Image = last
+		case "privileged":
+			privileged, err := strconv.ParseBool(last)
+			if err != nil {
+				return fmt.Errorf("invalid machine %q: invalid privileged value %q", value, last)
+			}
+			machine.Spec.Privileged = privileged
+		case "port":
+			for _, p := range vals {
+				mapping, err := parsePortMapping(p)
+				if err != nil {
+					return fmt.Errorf("invalid machine %q: %w", value, err)
+				}
+				machine.Spec.PortMappings = addPortMapping(machine.Spec.PortMappings, mapping)
+			}
+		case "network":
+			machine.Spec.Networks = vals
+		default:
+			return fmt.Errorf("invalid machine %q: unknown key %q", value, key)
+		}
+	}
+	return nil
+}
+
+// addPortMapping adds mapping to mappings, replacing an existing mapping of
+// the same container port and protocol.
+func addPortMapping(mappings []config.PortMapping, mapping config.PortMapping) []config.PortMapping {
+	protocol := func(m config.PortMapping) string {
+		if m.Protocol == "" {
+			return "tcp"
+		}
+		return m.Protocol
+	}
+	for i, m := range mappings {
+		if m.ContainerPort == mapping.ContainerPort && protocol(m) == protocol(mapping) {
+			mappings[i] = mapping
+			return mappings
+		}
+	}
+	return append(mappings, mapping)
+}
+
+// port flags can be in the form of:
+// -p 22 (container port, random host port)
+// -p 2222:22 (host port and container port)
+// -p 127.0.0.1:2222:22 (host address, host port and container port)
+// each optionally followed by the protocol:
+// -p 53:53/udp
+func parsePortMapping(p string) (config.PortMapping, error) {
+	mapping := config.PortMapping{}
+	ports, protocol, ok := strings.Cut(p, "/")
+	if ok {
+		if protocol != "tcp" && protocol != "udp" {
+			return mapping, fmt.Errorf("invalid port mapping %q: protocol must be tcp or udp", p)
+		}
+		mapping.Protocol = protocol
+	}
+
+	parsePort := func(s string) (uint16, error) {
+		port, err := strconv.ParseUint(s, 10, 16)
+		if err != nil || port == 0 {
+			return 0, fmt.Errorf("invalid port mapping %q: invalid port %q", p, s)
+		}
+		return uint16(port), nil
+	}
+
+	rest, containerPort := "", ports
+	if i := strings.LastIndex(ports, ":"); i >= 0 {
+		rest, containerPort = ports[:i], ports[i+1:]
+	}
+	port, err := parsePort(containerPort)
+	if err != nil {
+		return mapping, err
+	}
+	mapping.ContainerPort = port
+	if rest == "" {
+		return mapping, nil
+	}
+
+	hostPort := rest
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		mapping.Address = strings.TrimSuffix(strings.TrimPrefix(rest[:i], "["), "]")
+		hostPort = rest[i+1:]
+	}
+	if mapping.HostPort, err = parsePort(hostPort); err != nil {
+		return mapping, err
+	}
+	return mapping, nil
 }
 
 // volume flags can be in the form of:
@@ -8,23 +8,37 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"reflect"
 
+	"github.com/ghodss/yaml"
 	"github.com/k0sproject/bootloose/pkg/config"
 	"github.com/spf13/cobra"
 )
 
+type configGetOptions struct {
+	output string
+}
+
 func NewConfigGetCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "get",
+	opts := &configGetOptions{}
+	cmd := &cobra.Command{
+		Use:   "get [PATH]",
 		Short: "Get config file information",
-		RunE:  getConfig,
+		Long: `Get the whole config file, or the value at PATH. Keys are the ones used in the
+	config file, eg. 'machines[0].spec.portMappings[0].hostPort'. Wildcards, slices and
+	filters can be used to select several values: 'machines[*].spec.image',
+	'machines[1:]', 'machines[?(@.spec.name == "worker%d")].count'.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: opts.getConfig,
 	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output formatting options: {json,yaml,raw}. Strings are printed raw and other values as json by default.")
+	return cmd
 }
 
-func getConfig(cmd *cobra.Command, args []string) error {
-	c, err := config.NewConfigFromFile(clusterConfigFile(cmd))
+func (opts *configGetOptions) getConfig(cmd *cobra.Command, args []string) error {
+	c, err := config.NewConfigFromFile(clusterConfigFile(cmd), configLoadOptions(cmd)...)
 	if err != nil {
 		return err
 	}
@@ -38,15 +52,69 @@ func getConfig(cmd *cobra.Command, args []string) error {
 	} else {
 		detail = c
 	}
-	if reflect.ValueOf(detail).Kind() != reflect.String {
+
+	out := cmd.OutOrStdout()
+	switch opts.output {
+	case "":
+		if reflect.ValueOf(detail).Kind() != reflect.String {
+			res, err := json.MarshalIndent(detail, "", "  ")
+			if err != nil {
+				log.Println(err)
+				return errors.New("cannot convert result to json")
+			}
+			fmt.Fprintf(out, "%s", res)
+		} else {
+			fmt.Fprintf(out, "%s", detail)
+		}
+	case "json":
 		res, err := json.MarshalIndent(detail, "", "  ")
 		if err != nil {
-			log.Println(err)
-			return errors.New("cannot convert result to json")
+			return fmt.Errorf("cannot convert result to json: %w", err)
 		}
-		fmt.Printf("%s", res)
-	} else {
-		fmt.Printf("%s", detail)
+		fmt.Fprintf(out, "%s\n", res)
+	case "yaml":
+		res, err := yaml.Marshal(detail)
+		if err != nil {
+			return fmt.Errorf("cannot convert result to yaml: %w", err)
+		}
+		fmt.Fprintf(out, "%s", res)
+	case "raw":
+		return writeRaw(out, detail)
+	default:
+		return fmt.Errorf("unknown output format '%s'", opts.output)
 	}
 	return nil
 }
+
+// writeRaw prints scalars as is and lists one element per line. Objects are
+// printed as compact json.
+func writeRaw(w io.Writer, detail interface{}) error {
+	v := reflect.ValueOf(detail)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			if err := writeRawValue(w, v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return writeRawValue(w, detail)
+}
+
+func writeRawValue(w io.Writer, detail interface{}) error {
+	switch reflect.ValueOf(detail).Kind() {
+	case reflect.Invalid:
+		_, err := fmt.Fprintln(w)
+		return err
+	case reflect.Pointer, reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		res, err := json.Marshal(detail)
+		if err != nil {
+			return fmt.Errorf("cannot convert result to json: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", res)
+		return err
+	default:
+		_, err := fmt.Fprintln(w, detail)
+		return err
+	}
+}
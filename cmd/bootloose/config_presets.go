@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewConfigPresetsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "presets",
+		Short: "List the built-in presets and their parameters",
+		Long: `List the built-in presets usable with 'config create --preset NAME', along with
+	their parameters and default values. Parameters are set with '--param NAME=VALUE'.`,
+		Args: cobra.NoArgs,
+		RunE: listPresets,
+	}
+}
+
+func listPresets(cmd *cobra.Command, _ []string) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for i, preset := range config.Presets() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", preset.Name, preset.Description)
+		for _, param := range preset.Params {
+			fmt.Fprintf(w, "  %s=%s\t%s\n", param.Name, param.Default, param.Description)
+		}
+	}
+	return w.Flush()
+}
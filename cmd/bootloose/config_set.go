@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewConfigSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set PATH VALUE",
+		Short: "Set a value in the config file",
+		Long: `Set a value in the config file, using the same path syntax as 'config get'.
+	Missing slice elements and keys are created, eg. 'config set machines[1].spec.image IMAGE'
+	adds a second machine template. Composite values such as lists are given in YAML
+	syntax: 'config set machines[0].spec.networks "[net1, net2]"'.
+	The rest of the file, comments and variable references included, is kept as is.`,
+		Args: cobra.ExactArgs(2),
+		RunE: setConfig,
+	}
+}
+
+func setConfig(cmd *cobra.Command, args []string) error {
+	return updateConfigFile(cmd, func(data []byte) ([]byte, error) {
+		return config.SetValueInYAML(data, args[0], args[1])
+	})
+}
+
+// updateConfigFile applies update to the YAML of the config file and writes
+// it back if the result is a valid configuration. The file is not
+// interpolated, comments and references are kept.
+func updateConfigFile(cmd *cobra.Command, update func([]byte) ([]byte, error)) error {
+	path := clusterConfigFile(cmd)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data, err = update(data)
+	if err != nil {
+		return err
+	}
+	c, err := config.NewConfigFromYAML(data, config.WithBaseDir(filepath.Dir(path)))
+	if err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return replaceFile(path, data, info.Mode().Perm())
+}
+
+// replaceFile writes a temporary file next to path and renames it over path,
+// so that a failed write leaves the original file untouched.
+func replaceFile(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
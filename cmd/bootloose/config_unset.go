@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func NewConfigUnsetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset PATH",
+		Short: "Unset a value in the config file",
+		Long: `Unset a value in the config file, using the same path syntax as 'config get'.
+	Fields are reset to their default value, slice elements and keys are removed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: unsetConfig,
+	}
+}
+
+func unsetConfig(cmd *cobra.Command, args []string) error {
+	return updateConfigFile(cmd, func(data []byte) ([]byte, error) {
+		return config.UnsetValueInYAML(data, args[0])
+	})
+}
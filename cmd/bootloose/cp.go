@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"errors"
+	"io"
+
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type cpOptions struct {
+	all      bool
+	selector string
+	archive  bool
+}
+
+func NewCpCommand() *cobra.Command {
+	opts := &cpOptions{}
+	cmd := &cobra.Command{
+		Use:   "cp SRC DEST",
+		Short: "Copy files between the host and machines",
+		Long: `Copy files or directories between the host and machines, eg. 'cp ./k0s
+	node0:/usr/local/bin/k0s' or 'cp node1:/var/log/k0s.log .'. Machine paths are
+	written HOSTNAME:PATH, or :PATH along with --all or --selector to copy to
+	several machines. A '-' SRC reads a tar archive from the standard input and
+	extracts it into the machines DEST directory, a '-' DEST writes a tar archive
+	to the standard output. File modes are kept.`,
+		Args: cobra.ExactArgs(2),
+		RunE: opts.cp,
+	}
+	cmd.Flags().BoolVarP(&opts.all, "all", "a", false, "Copy to all the running machines")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "Copy to the machines matching a label selector")
+	cmd.Flags().BoolVar(&opts.archive, "archive", false, "Keep the owner of the files copied to machines instead of root")
+	return cmd
+}
+
+func (opts *cpOptions) cp(cmd *cobra.Command, args []string) error {
+	src, dest := cluster.ParseCopyPath(args[0]), cluster.ParseCopyPath(args[1])
+	switch {
+	case src.Remote && dest.Remote:
+		return errors.New("copying between machines is not supported, copy to the host first")
+	case !src.Remote && !dest.Remote:
+		return errors.New("either SRC or DEST has to be a machine path, eg. node0:/tmp")
+	}
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+
+	if src.Remote {
+		if opts.all || opts.selector != "" || src.Hostname == "" {
+			return errors.New("copying from machines requires a single HOSTNAME:PATH source")
+		}
+		machines, err := c.SelectMachines(false, "", []string{src.Hostname})
+		if err != nil {
+			return err
+		}
+		return c.CopyFrom(machines[0], src.Path, dest.Path, cmd.OutOrStdout())
+	}
+
+	var hostnames []string
+	if dest.Hostname != "" {
+		hostnames = append(hostnames, dest.Hostname)
+	}
+	machines, err := c.SelectMachines(opts.all, opts.selector, hostnames)
+	if err != nil {
+		return err
+	}
+	var stdin io.Reader
+	if src.Path == "-" {
+		stdin = cmd.InOrStdin()
+	}
+	return c.CopyTo(machines, src.Path, dest.Path, opts.archive, stdin)
+}
@@ -6,22 +6,29 @@ package bootloose
 
 import (
 	"github.com/spf13/cobra"
-
-	"github.com/k0sproject/bootloose/pkg/cluster"
 )
 
+type createOptions struct {
+	autoPorts bool
+}
+
 func NewCreateCommand() *cobra.Command {
-	return &cobra.Command{
+	opts := &createOptions{}
+	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a cluster",
-		RunE:  create,
+		Long: `Create a cluster. The host ports of the machines are checked before creating
+	them, a port already in use failing the creation unless --auto-ports is given.`,
+		RunE: opts.create,
 	}
+	cmd.Flags().BoolVar(&opts.autoPorts, "auto-ports", false, "Let docker allocate a random host port when a configured host port is not available")
+	return cmd
 }
 
-func create(cmd *cobra.Command, _ []string) error {
-	cluster, err := cluster.NewFromFile(clusterConfigFile(cmd))
+func (opts *createOptions) create(cmd *cobra.Command, _ []string) error {
+	cluster, err := loadCluster(cmd)
 	if err != nil {
 		return err
 	}
-	return cluster.Create()
+	return cluster.SetAutoHostPorts(opts.autoPorts).Create()
 }
@@ -6,8 +6,6 @@ package bootloose
 
 import (
 	"github.com/spf13/cobra"
-
-	"github.com/k0sproject/bootloose/pkg/cluster"
 )
 
 func NewDeleteCommand() *cobra.Command {
@@ -19,7 +17,7 @@ func NewDeleteCommand() *cobra.Command {
 }
 
 func delete(cmd *cobra.Command, args []string) error {
-	cluster, err := cluster.NewFromFile(clusterConfigFile(cmd))
+	cluster, err := loadCluster(cmd)
 	if err != nil {
 		return err
 	}
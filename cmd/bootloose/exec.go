@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+type execOptions struct {
+	all      bool
+	selector string
+	parallel int
+	json     bool
+}
+
+func NewExecCommand() *cobra.Command {
+	opts := &execOptions{}
+	cmd := &cobra.Command{
+		Use:   "exec [--all|--selector SELECTOR|HOSTNAME...] -- COMMAND [ARG...]",
+		Short: "Run a command on machines",
+		Long: `Run a command on machines with docker exec, which doesn't need their SSH port
+	to be mapped. The command runs on the given machines, on all the running ones
+	with --all, or on the ones whose labels match --selector, eg. 'role=worker' or
+	'role!=lb,zone'. The output lines are prefixed with the machine hostname. The
+	command exits with the exit status of the command when run on a single
+	machine, 1 when it failed on any of several machines.`,
+		RunE: opts.exec,
+	}
+	cmd.Flags().BoolVarP(&opts.all, "all", "a", false, "Run the command on all the running machines")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "Run the command on the machines matching a label selector")
+	cmd.Flags().IntVarP(&opts.parallel, "parallel", "p", 10, "Number of machines to run the command on at once, 0 for all")
+	cmd.Flags().BoolVar(&opts.json, "json", false, "Print a JSON summary with the output and exit code of each machine")
+	return cmd
+}
+
+func (opts *execOptions) exec(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 || dash == len(args) {
+		return errors.New("the command to run has to be given after --")
+	}
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	machines, err := c.SelectMachines(opts.all, opts.selector, args[:dash])
+	if err != nil {
+		return err
+	}
+
+	execOpts := cluster.ExecOptions{Parallel: opts.parallel}
+	if !opts.json {
+		execOpts.Stdout, execOpts.Stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		execOpts.Color = os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+	}
+	results := c.Exec(machines, args[dash:], execOpts)
+	if opts.json {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	}
+	err = cluster.ExecErr(results)
+	if err != nil && !opts.json {
+		log.Error(err)
+	}
+	return err
+}
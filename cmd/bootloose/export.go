@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the cluster to the configuration of other tools",
+	}
+	cmd.AddCommand(NewExportK0sctlCommand())
+	return cmd
+}
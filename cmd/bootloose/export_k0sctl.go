@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type exportK0sctlOptions struct {
+	cluster.K0sctlOptions
+}
+
+func NewExportK0sctlCommand() *cobra.Command {
+	opts := &exportK0sctlOptions{}
+	cmd := &cobra.Command{
+		Use:   "k0sctl",
+		Short: "Print a k0sctl cluster configuration of the machines",
+		Long: `Print a k0sctl cluster configuration of the running machines, eg.
+	'bootloose export k0sctl --k0s-version v1.30.0+k0s.0 | k0sctl apply --config -'.
+	The k0s role of the machines is taken from their 'role' label, or guessed from
+	their name, eg. 'controller%d' or 'worker%d'. With --bastion, k0sctl connects
+	to the machines at their private container addresses through the given machine.`,
+		Args: cobra.NoArgs,
+		RunE: opts.export,
+	}
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the k0sctl cluster, defaults to the cluster name")
+	cmd.Flags().StringVar(&opts.K0sVersion, "k0s-version", "", "Version of k0s to install, the latest when empty")
+	cmd.Flags().BoolVar(&opts.PrivateAddresses, "private-addresses", false, "Set the private address of the machines to their container address")
+	cmd.Flags().StringVar(&opts.Bastion, "bastion", "", "Hostname of the machine to use as SSH bastion to reach the private machine addresses")
+	return cmd
+}
+
+func (opts *exportK0sctlOptions) export(cmd *cobra.Command, _ []string) error {
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return c.K0sctl(cmd.OutOrStdout(), opts.K0sctlOptions)
+}
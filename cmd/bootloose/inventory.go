@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type inventoryOptions struct {
+	output     string
+	connection string
+	list       bool
+	host       string
+}
+
+func NewInventoryCommand() *cobra.Command {
+	opts := &inventoryOptions{}
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Print an Ansible inventory of the machines",
+		Long: `Print an Ansible inventory of the running machines, with a group per machine
+	template and per machine label, eg. 'role_controller' for the 'role: controller'
+	label. With --list and --host, the command follows the dynamic inventory script
+	protocol and can be called by Ansible from an executable wrapper script.`,
+		Args: cobra.NoArgs,
+		RunE: opts.inventory,
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "ini", "Output format: {ini,yaml,json}")
+	cmd.Flags().StringVar(&opts.connection, "connection", cluster.InventorySSH, "How Ansible connects to the machines: {ssh,docker}")
+	cmd.Flags().BoolVar(&opts.list, "list", false, "Print the whole inventory as a dynamic inventory script")
+	cmd.Flags().StringVar(&opts.host, "host", "", "Print the variables of a host as a dynamic inventory script")
+	return cmd
+}
+
+func (opts *inventoryOptions) inventory(cmd *cobra.Command, _ []string) error {
+	if opts.list && opts.host != "" {
+		return errors.New("--list and --host are mutually exclusive")
+	}
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	inventory, err := c.Inventory(opts.connection)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	switch {
+	case opts.list:
+		return inventory.WriteJSON(out)
+	case opts.host != "":
+		return inventory.WriteHostJSON(out, opts.host)
+	}
+	switch opts.output {
+	case "ini":
+		return inventory.WriteINI(out)
+	case "yaml":
+		return inventory.WriteYAML(out)
+	case "json":
+		return inventory.WriteJSON(out)
+	default:
+		return fmt.Errorf("unknown output format '%s'", opts.output)
+	}
+}
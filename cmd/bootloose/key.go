@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "key",
+		Short: "Manage the public keys machines can refer to",
+		Long: `Manage the key store, the public keys machines refer to by name with publicKey
+	and publicKeys to authorize them for root. The store is located in the user
+	configuration directory, eg. ~/.config/bootloose/keys, or at
+	$BOOTLOOSE_KEY_STORE. The rotate command replaces the cluster key pair instead.`,
+	}
+	cmd.AddCommand(NewKeyAddCommand())
+	cmd.AddCommand(NewKeyListCommand())
+	cmd.AddCommand(NewKeyRemoveCommand())
+	cmd.AddCommand(NewKeyRotateCommand())
+	cmd.AddCommand(NewKeyShowCommand())
+	return cmd
+}
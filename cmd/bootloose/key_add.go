@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type keyAddOptions struct {
+	force bool
+}
+
+func NewKeyAddCommand() *cobra.Command {
+	opts := &keyAddOptions{}
+	cmd := &cobra.Command{
+		Use:   "add NAME [FILE|-]",
+		Short: "Add a public key to the key store",
+		Long: `Add a public key in the authorized_keys format to the key store, read from FILE,
+	eg. ~/.ssh/id_ed25519.pub, or from the standard input. An existing key of the
+	same name is only replaced with --force, eg. to rotate it.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: opts.add,
+	}
+	cmd.Flags().BoolVarP(&opts.force, "force", "f", false, "Replace an existing key of the same name")
+	return cmd
+}
+
+func (opts *keyAddOptions) add(cmd *cobra.Command, args []string) error {
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if store.Exists(name) && !opts.force {
+		return fmt.Errorf("key '%s' already exists, use --force to replace it", name)
+	}
+
+	var data []byte
+	if len(args) < 2 || args[1] == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(args[1])
+	}
+	if err != nil {
+		return err
+	}
+	if err := store.Init(); err != nil {
+		return err
+	}
+	if err := store.Store(name, string(data)); err != nil {
+		return err
+	}
+	info, err := store.Info(name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s %s\n", info.Name, info.Type, info.Fingerprint)
+	return nil
+}
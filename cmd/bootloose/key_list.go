@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type keyListOptions struct {
+	output string
+}
+
+func NewKeyListCommand() *cobra.Command {
+	opts := &keyListOptions{}
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the keys of the key store",
+		Args:    cobra.NoArgs,
+		RunE:    opts.list,
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "table", "Output formatting options: {json,table}.")
+	return cmd
+}
+
+func (opts *keyListOptions) list(cmd *cobra.Command, _ []string) error {
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+	names, err := store.List()
+	if err != nil {
+		return err
+	}
+	keys := []*cluster.KeyInfo{}
+	for _, name := range names {
+		info, err := store.Info(name)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, info)
+	}
+
+	switch opts.output {
+	case "json":
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(keys)
+	case "table":
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tFINGERPRINT\tCOMMENT")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.Name, key.Type, key.Fingerprint, key.Comment)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown formatter '%s'", opts.output)
+	}
+}
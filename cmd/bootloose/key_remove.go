@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewKeyRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove NAME...",
+		Aliases: []string{"rm"},
+		Short:   "Remove keys from the key store",
+		Long: `Remove keys from the key store. Machines already created keep them authorized,
+	machines referring to them can't be created anymore.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: removeKeys,
+	}
+}
+
+func removeKeys(_ *cobra.Command, args []string) error {
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := store.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
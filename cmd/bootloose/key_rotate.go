@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type keyRotateOptions struct {
+	dryRun       bool
+	deferStopped bool
+}
+
+func NewKeyRotateCommand() *cobra.Command {
+	opts := &keyRotateOptions{}
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the cluster key pair",
+		Long: `Replace the cluster key pair, cluster.privateKey. The new public key is
+	authorized on the running machines and login with it is verified before the
+	key files are replaced and the old key is removed from the machines. Machines
+	using key store keys are left as is. Stopped machines make the rotation fail,
+	unless --defer-stopped updates them when they next start.`,
+		Args: cobra.NoArgs,
+		RunE: opts.rotate,
+	}
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the machines the rotation would update")
+	cmd.Flags().BoolVar(&opts.deferStopped, "defer-stopped", false, "Update stopped machines when they next start instead of failing")
+	return cmd
+}
+
+func (opts *keyRotateOptions) rotate(cmd *cobra.Command, _ []string) error {
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return c.RotateKey(cmd.Context(), cluster.RotateKeyOptions{
+		DryRun:       opts.dryRun,
+		DeferStopped: opts.deferStopped,
+		Out:          cmd.OutOrStdout(),
+	})
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type keyShowOptions struct {
+	output string
+}
+
+func NewKeyShowCommand() *cobra.Command {
+	opts := &keyShowOptions{}
+	cmd := &cobra.Command{
+		Use:   "show NAME",
+		Short: "Show a key of the key store",
+		Long: `Show a key of the key store, its type, fingerprint and comment, or with '-o key'
+	the key alone in the authorized_keys format.`,
+		Args: cobra.ExactArgs(1),
+		RunE: opts.show,
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output formatting options: {text,key}.")
+	return cmd
+}
+
+func (opts *keyShowOptions) show(cmd *cobra.Command, args []string) error {
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+	info, err := store.Info(args[0])
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	switch opts.output {
+	case "key":
+		fmt.Fprintln(out, info.Key)
+	case "text":
+		fmt.Fprintf(out, "Name:        %s\n", info.Name)
+		fmt.Fprintf(out, "Type:        %s\n", info.Type)
+		fmt.Fprintf(out, "Fingerprint: %s\n", info.Fingerprint)
+		fmt.Fprintf(out, "Comment:     %s\n", info.Comment)
+		fmt.Fprintf(out, "Key:         %s\n", info.Key)
+	default:
+		return fmt.Errorf("unknown formatter '%s'", opts.output)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type netOptions struct {
+	helperImage string
+}
+
+func NewNetCommand() *cobra.Command {
+	opts := &netOptions{}
+	cmd := &cobra.Command{
+		Use:   "net",
+		Short: "Inject network faults between cluster machines",
+		Long: `Inject network faults between cluster machines. Partitions disconnect machines
+	from their docker networks, traffic shaping uses tc and netem. tc runs in the
+	machines unless a helper image is given, in which case it runs in a container
+	sharing the machine network namespace, eg. --helper-image nicolaka/netshoot.`,
+	}
+	cmd.PersistentFlags().StringVar(&opts.helperImage, "helper-image", "", "Image of a helper container providing tc")
+
+	cmd.AddCommand(
+		NewNetPartitionCommand(opts),
+		NewNetShapeCommand(opts),
+		NewNetHealCommand(opts),
+		NewNetApplyCommand(opts),
+		NewNetRevertCommand(opts),
+	)
+
+	return cmd
+}
+
+func (opts *netOptions) loadCluster(cmd *cobra.Command) (*cluster.Cluster, error) {
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return c.SetNetworkHelper(opts.helperImage), nil
+}
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewNetApplyCommand(opts *netOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the faults described in the config file",
+		Args:  cobra.NoArgs,
+		RunE:  opts.apply,
+	}
+}
+
+func (opts *netOptions) apply(cmd *cobra.Command, _ []string) error {
+	cluster, err := opts.loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return cluster.ApplyFaults()
+}
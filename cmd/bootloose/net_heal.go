@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewNetHealCommand(opts *netOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "heal [HOSTNAME...]",
+		Short: "Reconnect machines to their networks and remove traffic shaping",
+		Long: `Reconnect machines to their networks and remove traffic shaping. All the
+	machines are healed when no hostname is given.`,
+		RunE: opts.heal,
+	}
+}
+
+func (opts *netOptions) heal(cmd *cobra.Command, args []string) error {
+	cluster, err := opts.loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return cluster.Heal(args)
+}
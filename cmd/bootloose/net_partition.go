@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type netPartitionOptions struct {
+	*netOptions
+	network string
+}
+
+func NewNetPartitionCommand(netOpts *netOptions) *cobra.Command {
+	opts := &netPartitionOptions{netOptions: netOpts}
+	cmd := &cobra.Command{
+		Use:   "partition HOSTNAME...",
+		Short: "Disconnect machines from their networks",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  opts.partition,
+	}
+	cmd.Flags().StringVar(&opts.network, "network", "", "Only disconnect the machines from this network")
+	return cmd
+}
+
+func (opts *netPartitionOptions) partition(cmd *cobra.Command, args []string) error {
+	cluster, err := opts.loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return cluster.Partition(args, opts.network)
+}
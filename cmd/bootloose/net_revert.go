@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewNetRevertCommand(opts *netOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revert",
+		Short: "Revert the faults described in the config file",
+		Long: `Revert the faults described in the config file by healing the machines they
+	affect.`,
+		Args: cobra.NoArgs,
+		RunE: opts.revert,
+	}
+}
+
+func (opts *netOptions) revert(cmd *cobra.Command, _ []string) error {
+	cluster, err := opts.loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return cluster.RevertFaults()
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+type netShapeOptions struct {
+	*netOptions
+	shaping config.Shaping
+	iface   string
+}
+
+func NewNetShapeCommand(netOpts *netOptions) *cobra.Command {
+	opts := &netShapeOptions{netOptions: netOpts}
+	cmd := &cobra.Command{
+		Use:   "shape HOSTNAME...",
+		Short: "Add latency, packet loss or bandwidth limits to machines",
+		Long: `Add latency, packet loss or bandwidth limits to the outgoing traffic of machines,
+	eg. 'net shape node2 --delay 100ms --loss 5% --rate 1mbit'. Shaping the same machine
+	again replaces the previous settings, 'net heal' removes them.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: opts.shape,
+	}
+	cmd.Flags().StringVar(&opts.shaping.Delay, "delay", "", "Delay added to outgoing packets, eg. 100ms")
+	cmd.Flags().StringVar(&opts.shaping.Jitter, "jitter", "", "Random variation of the delay, eg. 10ms")
+	cmd.Flags().StringVar(&opts.shaping.Loss, "loss", "", "Percentage of dropped outgoing packets, eg. 5%")
+	cmd.Flags().StringVar(&opts.shaping.Rate, "rate", "", "Outgoing bandwidth limit, eg. 1mbit")
+	cmd.Flags().StringVar(&opts.iface, "interface", "", "Only shape this network interface instead of all of them")
+	return cmd
+}
+
+func (opts *netShapeOptions) shape(cmd *cobra.Command, args []string) error {
+	cluster, err := opts.loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	return cluster.Shape(args, opts.shaping, opts.iface)
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type portForwardOptions struct {
+	address string
+}
+
+func NewPortForwardCommand() *cobra.Command {
+	opts := &portForwardOptions{}
+	cmd := &cobra.Command{
+		Use:   "port-forward HOSTNAME [HOST_PORT:]CONTAINER_PORT[/PROTOCOL]...",
+		Short: "Forward host ports to a machine",
+		Long: `Forward host ports to a machine until interrupted, eg. 'port-forward node0 8443:443
+	53/udp'. Unlike port mappings, forwards don't require recreating the machine.
+	Without host port, the same port as the machine one is used, ':443' picks a
+	random host port. Connections go to the machine IP address, or through docker
+	exec with socat or nc when this address isn't reachable from the host.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: opts.portForward,
+	}
+	cmd.Flags().StringVar(&opts.address, "address", "127.0.0.1", "Host address to listen on")
+	return cmd
+}
+
+func (opts *portForwardOptions) portForward(cmd *cobra.Command, args []string) error {
+	forwards := make([]cluster.PortForward, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		forward, err := cluster.ParsePortForward(arg)
+		if err != nil {
+			return err
+		}
+		forwards = append(forwards, forward)
+	}
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return c.PortForward(ctx, args[0], opts.address, forwards)
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+type proxyOptions struct {
+	listen string
+}
+
+func NewProxyCommand() *cobra.Command {
+	opts := &proxyOptions{}
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Run a SOCKS5 proxy into the cluster network",
+		Long: `Run a SOCKS5 proxy until interrupted. Machine hostnames are resolved to the
+	machine addresses, eg. 'curl --socks5-hostname 127.0.0.1:1080 https://node1:6443'
+	reaches port 6443 of node1 without mapping it to the host.`,
+		Args: cobra.NoArgs,
+		RunE: opts.proxy,
+	}
+	cmd.Flags().StringVar(&opts.listen, "listen", "127.0.0.1:1080", "Address to listen on")
+	return cmd
+}
+
+func (opts *proxyOptions) proxy(cmd *cobra.Command, _ []string) error {
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return c.Proxy(ctx, opts.listen)
+}
@@ -8,6 +8,9 @@ import (
 	"context"
 	"os"
 
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/k0sproject/bootloose/pkg/config"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -16,10 +19,16 @@ const ConfigFile = "bootloose.yaml"
 
 type contextKey string
 
-const configFileKey contextKey = "configFile"
+const (
+	configFileKey          contextKey = "configFile"
+	strictInterpolationKey contextKey = "strictInterpolation"
+)
 
 func NewRootCommand(ctx context.Context) *cobra.Command {
-	var configFile string
+	var (
+		configFile          string
+		strictInterpolation bool
+	)
 
 	cmd := &cobra.Command{
 		Use:           "bootloose",
@@ -31,10 +40,13 @@ func NewRootCommand(ctx context.Context) *cobra.Command {
 	cmd.SetContext(ctx)
 
 	cmd.PersistentFlags().StringVarP(&configFile, "config", "c", ConfigFile, "Cluster configuration file")
+	cmd.PersistentFlags().BoolVar(&strictInterpolation, "strict-interpolation", false, "Fail on undefined variables referenced from the configuration file")
 
 	cmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
 		if flag := cmd.Flags().Lookup("config"); flag != nil && !flag.Hidden {
-			cmd.SetContext(context.WithValue(cmd.Context(), configFileKey, configFile))
+			ctx := context.WithValue(cmd.Context(), configFileKey, configFile)
+			ctx = context.WithValue(ctx, strictInterpolationKey, strictInterpolation)
+			cmd.SetContext(ctx)
 		}
 	}
 
@@ -46,6 +58,15 @@ func NewRootCommand(ctx context.Context) *cobra.Command {
 		NewStartCommand(),
 		NewStopCommand(),
 		NewSSHCommand(),
+		NewExecCommand(),
+		NewCpCommand(),
+		NewSSHConfigCommand(),
+		NewInventoryCommand(),
+		NewExportCommand(),
+		NewNetCommand(),
+		NewPortForwardCommand(),
+		NewProxyCommand(),
+		NewKeyCommand(),
 	)
 
 	// hide config flag from commands that do not need it
@@ -53,8 +74,10 @@ func NewRootCommand(ctx context.Context) *cobra.Command {
 		NewVersionCommand(),
 	} {
 		cmd.AddCommand(configlessCmd)
-		if flag := configlessCmd.Flags().Lookup("config"); flag != nil {
-			flag.Hidden = true
+		for _, name := range []string{"config", "strict-interpolation"} {
+			if flag := configlessCmd.Flags().Lookup(name); flag != nil {
+				flag.Hidden = true
+			}
 		}
 	}
 
@@ -77,3 +100,44 @@ func clusterConfigFile(cmd *cobra.Command) string {
 	return configFile(cfg)
 }
 
+// configLoadOptions returns the options to use when loading the cluster
+// configuration file.
+func configLoadOptions(cmd *cobra.Command) []config.LoadOption {
+	var opts []config.LoadOption
+	if strict, ok := cmd.Context().Value(strictInterpolationKey).(bool); ok && strict {
+		opts = append(opts, config.WithStrictInterpolation())
+	}
+	return opts
+}
+
+// keyStore returns the public key store, at $BOOTLOOSE_KEY_STORE or its
+// default location.
+func keyStore() (*cluster.KeyStore, error) {
+	path := os.Getenv("BOOTLOOSE_KEY_STORE")
+	if path == "" {
+		var err error
+		if path, err = cluster.DefaultKeyStorePath(); err != nil {
+			return nil, err
+		}
+	}
+	return cluster.NewKeyStore(path), nil
+}
+
+// loadCluster loads the cluster described in the configuration file. The key
+// store is only looked up for the machines using its keys, and left out when
+// its location is unknown.
+func loadCluster(cmd *cobra.Command) (*cluster.Cluster, error) {
+	c, err := cluster.NewFromFile(clusterConfigFile(cmd), configLoadOptions(cmd)...)
+	if err != nil {
+		return nil, err
+	}
+	if !c.UsesKeyStore() {
+		return c, nil
+	}
+	store, err := keyStore()
+	if err != nil {
+		log.Debugf("No key store: %v", err)
+		return c, nil
+	}
+	return c.SetKeyStore(store), nil
+}
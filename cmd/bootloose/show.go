@@ -33,7 +33,7 @@ func NewShowCommand() *cobra.Command {
 
 // show will show all machines in a given cluster.
 func (opts *showOptions) show(cmd *cobra.Command, args []string) error {
-	c, err := cluster.NewFromFile(configFile(clusterConfigFile(cmd)))
+	c, err := loadCluster(cmd)
 	if err != nil {
 		return err
 	}
@@ -7,32 +7,36 @@ package bootloose
 import (
 	"errors"
 	"fmt"
+	"os"
 	"os/user"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-
-	"github.com/k0sproject/bootloose/pkg/cluster"
 )
 
 type sshOptions struct {
 	verbose bool
+	tty     bool
 }
 
 func NewSSHCommand() *cobra.Command {
 	opts := &sshOptions{}
 	cmd := &cobra.Command{
-		Use:   "ssh",
+		Use:   "ssh [USER@]HOSTNAME [-- COMMAND...]",
 		Short: "SSH into a machine",
+		Long: `SSH into a machine, opening an interactive shell or running the given command.
+	The exit status of the command is the one of bootloose.`,
 		Args:  validateArgs,
 		RunE:  opts.ssh,
 	}
 	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "SSH verbose output")
+	cmd.Flags().BoolVarP(&opts.tty, "tty", "t", false, "Run the command in a pseudo terminal")
 	return cmd
 }
 
 func (opts *sshOptions) ssh(cmd *cobra.Command, args []string) error {
-	cluster, err := cluster.NewFromFile(clusterConfigFile(cmd))
+	cluster, err := loadCluster(cmd)
 	if err != nil {
 		return err
 	}
@@ -53,12 +57,18 @@ func (opts *sshOptions) ssh(cmd *cobra.Command, args []string) error {
 		}
 		username = user.Username
 	}
-	var remoteArgs []string
 	if opts.verbose {
-		remoteArgs = append(remoteArgs, "-v")
+		log.SetLevel(log.DebugLevel)
+	}
+	if !opts.tty {
+		return cluster.SSH(cmd.Context(), node, username, args[1:]...)
+	}
+	client, err := cluster.SSHClient(cmd.Context(), node, username)
+	if err != nil {
+		return err
 	}
-	remoteArgs = append(remoteArgs, args[1:]...)
-	return cluster.SSH(node, username, remoteArgs...)
+	defer client.Close()
+	return client.Terminal(strings.Join(args[1:], " "), os.Stdin, os.Stdout, os.Stderr)
 }
 
 func validateArgs(_ *cobra.Command, args []string) error {
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bootloose
+
+import (
+	"github.com/k0sproject/bootloose/pkg/cluster"
+	"github.com/spf13/cobra"
+)
+
+type sshConfigOptions struct {
+	user    string
+	include bool
+	file    string
+}
+
+func NewSSHConfigCommand() *cobra.Command {
+	opts := &sshConfigOptions{}
+	cmd := &cobra.Command{
+		Use:   "ssh-config",
+		Short: "Print an OpenSSH client config for the machines",
+		Long: `Print OpenSSH client config Host blocks for the running machines, so that
+	ssh, rsync, Ansible or editors can connect to them, eg. 'ssh cluster-node0'.
+	With --include, the blocks are written to a file included from ~/.ssh/config
+	instead. Setting cluster.sshConfig to this file keeps it updated when
+	creating, starting and deleting the cluster.`,
+		Args: cobra.NoArgs,
+		RunE: opts.sshConfig,
+	}
+	cmd.Flags().StringVarP(&opts.user, "user", "u", "root", "User to log in as")
+	cmd.Flags().BoolVar(&opts.include, "include", false, "Write the Host blocks to a file included from ~/.ssh/config")
+	cmd.Flags().StringVar(&opts.file, "file", "", "File the Host blocks are written to with --include, defaults to cluster.sshConfig or "+cluster.DefaultSSHConfig)
+	return cmd
+}
+
+func (opts *sshConfigOptions) sshConfig(cmd *cobra.Command, _ []string) error {
+	c, err := loadCluster(cmd)
+	if err != nil {
+		return err
+	}
+	if !opts.include {
+		return c.SSHConfig(cmd.OutOrStdout(), opts.user)
+	}
+	path := opts.file
+	if path == "" {
+		path = c.SSHConfigPath()
+	}
+	return c.WriteSSHConfigInclude(path, opts.user)
+}
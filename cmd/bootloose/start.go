@@ -6,8 +6,6 @@ package bootloose
 
 import (
 	"github.com/spf13/cobra"
-
-	"github.com/k0sproject/bootloose/pkg/cluster"
 )
 
 func NewStartCommand() *cobra.Command {
@@ -19,7 +17,7 @@ func NewStartCommand() *cobra.Command {
 }
 
 func start(cmd *cobra.Command, args []string) error {
-	cluster, err := cluster.NewFromFile(clusterConfigFile(cmd))
+	cluster, err := loadCluster(cmd)
 	if err != nil {
 		return err
 	}
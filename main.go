@@ -5,8 +5,10 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"github.com/k0sproject/bootloose/cmd/bootloose"
+	"github.com/k0sproject/bootloose/pkg/cluster"
 
 	_ "github.com/carlmjohnson/versioninfo" // Ensure version info is added to binary
 
@@ -15,6 +17,10 @@ import (
 
 func main() {
 	if err := bootloose.NewRootCommand(context.Background()).Execute(); err != nil {
+		// Commands run on machines exit with their own status.
+		if status, ok := cluster.ExitStatus(err); ok {
+			os.Exit(status)
+		}
 		log.Fatal(err)
 	}
 }
@@ -7,8 +7,18 @@ package network
 // https://github.com/moby/moby/blob/v28.3.3/api/types/network/endpoint.go#L12
 
 type EndpointSettings struct {
-	Gateway           string
-	IPAddress         string
-	IPPrefixLen       int
-	GlobalIPv6Address string
+	IPAMConfig          *EndpointIPAMConfig
+	Gateway             string
+	IPAddress           string
+	IPPrefixLen         int
+	IPv6Gateway         string
+	GlobalIPv6Address   string
+	GlobalIPv6PrefixLen int
+}
+
+// EndpointIPAMConfig holds the static addresses requested for an endpoint.
+// https://github.com/moby/moby/blob/v28.3.3/api/types/network/endpoint.go#L98
+type EndpointIPAMConfig struct {
+	IPv4Address string
+	IPv6Address string
 }
@@ -5,18 +5,15 @@
 package cluster
 
 import (
+	"bytes"
 	"context"
-	"crypto/ed25519"
-	"encoding/pem"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/ghodss/yaml"
 	dockercontainer "github.com/k0sproject/bootloose/pkg/api/docker/container"
@@ -25,6 +22,7 @@ import (
 	"github.com/k0sproject/bootloose/pkg/exec"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
 )
 
 // Container represents a running machine.
@@ -34,8 +32,10 @@ type Container struct {
 
 // Cluster is a running cluster.
 type Cluster struct {
-	spec     config.Config
-	keyStore *KeyStore
+	spec          config.Config
+	keyStore      *KeyStore
+	networkHelper string
+	autoHostPorts bool
 }
 
 // New creates a new cluster. It takes as input the description of the cluster
@@ -51,22 +51,22 @@ func New(conf config.Config) (*Cluster, error) {
 
 // NewFromYAML creates a new Cluster from a YAML serialization of its
 // configuration available in the provided string.
-func NewFromYAML(data []byte) (*Cluster, error) {
-	spec := config.Config{}
-	if err := yaml.Unmarshal(data, &spec); err != nil {
+func NewFromYAML(data []byte, opts ...config.LoadOption) (*Cluster, error) {
+	spec, err := config.NewConfigFromYAML(data, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return New(spec)
+	return New(*spec)
 }
 
 // NewFromFile creates a new Cluster from a YAML serialization of its
 // configuration available in the provided file.
-func NewFromFile(path string) (*Cluster, error) {
-	data, err := os.ReadFile(path)
+func NewFromFile(path string, opts ...config.LoadOption) (*Cluster, error) {
+	spec, err := config.NewConfigFromFile(path, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return NewFromYAML(data)
+	return New(*spec)
 }
 
 // SetKeyStore provides a store where to persist public keys for this Cluster.
@@ -75,6 +75,16 @@ func (c *Cluster) SetKeyStore(keyStore *KeyStore) *Cluster {
 	return c
 }
 
+// UsesKeyStore returns true when a machine public key is in the key store.
+func (c *Cluster) UsesKeyStore() bool {
+	for _, template := range c.spec.Machines {
+		if template.Spec.PublicKey != "" || len(template.Spec.PublicKeys) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // Name returns the cluster name.
 func (c *Cluster) Name() string {
 	return c.spec.Cluster.Name
@@ -116,6 +126,7 @@ func (c *Cluster) machine(spec *config.Machine, i int) *Machine {
 		spec:     spec,
 		name:     c.containerNameWithIndex(spec, i),
 		hostname: f(spec.Name, i),
+		index:    i,
 	}
 }
 
@@ -175,21 +186,10 @@ func (c *Cluster) ensureSSHKey() error {
 		return nil
 	}
 
-	// Generate the Ed25519 private and public key pair
-	// and convert it into an SSH key pair.
-	pub, priv, err := ed25519.GenerateKey(nil)
-	if err != nil {
-		return fmt.Errorf("failed to generate new Ed25519 key: %w", err)
-	}
-	sshPub, err := ssh.NewPublicKey(pub)
+	sshPrivBytes, sshPubBytes, err := generateKey(c.spec.Cluster.KeyType)
 	if err != nil {
-		return fmt.Errorf("failed to convert Ed25519 public key into SSH public key: %w", err)
-	}
-	privPEM, err := ssh.MarshalPrivateKey(priv, "")
-	if err != nil {
-		return fmt.Errorf("failed to convert Ed25519 private key into PEM block: %w", err)
+		return err
 	}
-	sshPubBytes, sshPrivBytes := ssh.MarshalAuthorizedKey(sshPub), pem.EncodeToMemory(privPEM)
 
 	// Save the key pair (unencrypted).
 	if err := os.WriteFile(path, sshPrivBytes, 0o600); err != nil {
@@ -211,18 +211,42 @@ chmod 700 $sshdir
 touch $sshdir/authorized_keys; chmod 600 $sshdir/authorized_keys
 `
 
+// keyNames returns the names of the public keys of a machine in the key store.
+func keyNames(machine *Machine) []string {
+	var names []string
+	if machine.spec.PublicKey != "" {
+		names = append(names, machine.spec.PublicKey)
+	}
+	return append(names, machine.spec.PublicKeys...)
+}
+
 func (c *Cluster) publicKey(machine *Machine) ([]byte, error) {
-	// Prefer the machine public key over the cluster-wide key.
-	if machine.spec.PublicKey != "" && c.keyStore != nil {
-		data, err := c.keyStore.Get(machine.spec.PublicKey)
-		if err != nil {
-			return nil, err
+	// Prefer the machine public keys over the cluster-wide key.
+	if names := keyNames(machine); len(names) > 0 {
+		if c.keyStore == nil {
+			return nil, fmt.Errorf("%s: no key store to get the machine public keys from, set BOOTLOOSE_KEY_STORE", machine.Hostname())
 		}
-		data = append(data, byte('\n'))
-		return data, err
+		var keys []byte
+		for _, name := range names {
+			data, err := c.keyStore.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, bytes.TrimSpace(data)...)
+			keys = append(keys, '\n')
+		}
+		return keys, nil
 	}
 
 	// Cluster global key
+	if c.spec.Cluster.AgentKey != "" {
+		signer, conn, err := c.agentSigner()
+		if err != nil {
+			return nil, err
+		}
+		conn.Close()
+		return ssh.MarshalAuthorizedKey(signer.PublicKey()), nil
+	}
 	if c.spec.Cluster.PrivateKey == "" {
 		return nil, errors.New("no SSH key provided")
 	}
@@ -256,7 +280,10 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 		cmd = machine.spec.Cmd
 	}
 
-	runArgs := c.createMachineRunArgs(machine, name, i)
+	runArgs, err := c.createMachineRunArgs(machine, name, i)
+	if err != nil {
+		return err
+	}
 	_, err = docker.Create(machine.spec.Image,
 		runArgs,
 		[]string{cmd},
@@ -267,19 +294,16 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 
 	if len(machine.spec.Networks) > 1 {
 		for _, network := range machine.spec.Networks[1:] {
-			log.Infof("Connecting %s to the %s network...", name, network)
-			if network == "bridge" {
-				if err := docker.ConnectNetwork(name, network); err != nil {
-					return err
-				}
-			} else {
-				if err := docker.ConnectNetworkWithAlias(name, network, machine.Hostname()); err != nil {
-					return err
-				}
+			if err := c.connectNetwork(machine, network); err != nil {
+				return err
 			}
 		}
 	}
 
+	if err := injectHostKey(machine); err != nil {
+		return fmt.Errorf("failed to inject a host key into %s: %w", name, err)
+	}
+
 	if err := docker.Start(name); err != nil {
 		return err
 	}
@@ -291,15 +315,36 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 	if err := copy(name, publicKey, "/root/.ssh/authorized_keys"); err != nil {
 		return err
 	}
+	if err := c.createUsers(machine, publicKey); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []string {
+// connectNetwork connects the machine to one of its networks, with its
+// hostname as alias and its static address if any.
+func (c *Cluster) connectNetwork(machine *Machine, network string) error {
+	name := machine.ContainerName()
+	log.Infof("Connecting %s to the %s network...", name, network)
+	if network == "bridge" {
+		return docker.ConnectNetwork(name, network)
+	}
+	ip, err := machine.spec.IPv4Address(network, machine.index)
+	if err != nil {
+		return err
+	}
+	if ip != "" {
+		return docker.ConnectNetworkWithIP(name, network, machine.Hostname(), ip)
+	}
+	return docker.ConnectNetworkWithAlias(name, network, machine.Hostname())
+}
+
+func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) ([]string, error) {
 	runArgs := []string{
 		"-it",
 		"--label", "io.k0sproject.bootloose.owner=bootloose",
-		"--label", "io.k0sproject.bootloose.cluster=" + c.spec.Cluster.Name,
+		"--label", clusterLabel + "=" + c.spec.Cluster.Name,
 		"--name", name,
 		"--hostname", machine.Hostname(),
 		"--tmpfs", "/run",
@@ -326,13 +371,27 @@ func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []s
 		runArgs = append(runArgs, "--mount", mount)
 	}
 
-	for _, mapping := range machine.spec.PortMappings {
+	for k, mapping := range machine.spec.PortMappings {
 		publish := ""
 		if mapping.Address != "" {
-			publish += f("%s:", mapping.Address)
+			publish += f("%s:", mapping.HostAddress())
+		}
+		hostPort := 0
+		if machine.hostPorts != nil {
+			hostPort = int(machine.hostPorts[k])
+		} else if mapping.HostPort != 0 {
+			hostPort = int(mapping.HostPort) + i
+		} else if mapping.HostPortRange != "" {
+			first, _, err := mapping.HostPorts()
+			if err != nil {
+				return nil, err
+			}
+			hostPort = int(first) + i
 		}
-		if mapping.HostPort != 0 {
-			publish += f("%d:", int(mapping.HostPort)+i)
+		if hostPort != 0 {
+			publish += f("%d:", hostPort)
+		} else if mapping.Address != "" {
+			publish += ":"
 		}
 		publish += f("%d", mapping.ContainerPort)
 		if mapping.Protocol != "" {
@@ -341,6 +400,15 @@ func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []s
 		runArgs = append(runArgs, "-p", publish)
 	}
 
+	envNames := make([]string, 0, len(machine.spec.Env))
+	for name := range machine.spec.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		runArgs = append(runArgs, "-e", f("%s=%s", name, machine.spec.Env[name]))
+	}
+
 	if machine.spec.Privileged {
 		runArgs = append(runArgs, "--privileged")
 	}
@@ -352,9 +420,16 @@ func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []s
 		if network != "bridge" {
 			runArgs = append(runArgs, "--network-alias", machine.Hostname())
 		}
+		ip, err := machine.spec.IPv4Address(network, machine.index)
+		if err != nil {
+			return nil, err
+		}
+		if ip != "" {
+			runArgs = append(runArgs, "--ip", ip)
+		}
 	}
 
-	return append(runArgs, machine.spec.ExtraArgs...)
+	return append(runArgs, machine.spec.ExtraArgs...), nil
 }
 
 // Create creates the cluster.
@@ -365,12 +440,38 @@ func (c *Cluster) Create() error {
 	if err := docker.IsRunning(); err != nil {
 		return err
 	}
+	hostPorts, err := c.planHostPorts()
+	if err != nil {
+		return err
+	}
 	for _, template := range c.spec.Machines {
 		if _, err := docker.PullIfNotPresent(template.Spec.Image, 2); err != nil {
 			return err
 		}
 	}
-	return c.forEachMachine(c.CreateMachine)
+	if err := c.createNetworks(); err != nil {
+		return err
+	}
+	if err := c.checkAddresses(); err != nil {
+		return err
+	}
+	err = c.forEachMachine(func(machine *Machine, i int) error {
+		machine.hostPorts = hostPorts[machine.ContainerName()]
+		return c.CreateMachine(machine, i)
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.updateHosts(); err != nil {
+		return err
+	}
+	if err := c.updateKnownHosts(); err != nil {
+		return err
+	}
+	if err := c.updateSSHConfig(); err != nil {
+		return err
+	}
+	return c.reportHostPorts()
 }
 
 // DeleteMachine remove a Machine from the cluster.
@@ -406,7 +507,16 @@ func (c *Cluster) Delete() error {
 	if err := docker.IsRunning(); err != nil {
 		return err
 	}
-	return c.forEachMachine(c.DeleteMachine)
+	if err := c.forEachMachine(c.DeleteMachine); err != nil {
+		return err
+	}
+	if err := c.removeSSHConfig(); err != nil {
+		return err
+	}
+	if err := c.removeKnownHosts(); err != nil {
+		return err
+	}
+	return c.deleteNetworks()
 }
 
 // Inspect will generate information about running or stopped machines.
@@ -488,26 +598,10 @@ func (c *Cluster) gatherMachines() (machines []*Machine, err error) {
 		m.spec.Volumes = volumes
 		m.spec.Cmd = strings.Join(inspect.Config.Cmd, ",")
 		m.ip = inspect.NetworkSettings.IPAddress
-		// Since Docker 29.x the IPAddress field is deprecated and will not be set at NetworkSettings level
-		// Instead we need to check the Networks map and pick first address we find
-		if m.ip == "" {
-			for _, netw := range inspect.NetworkSettings.Networks {
-				m.ip = netw.IPAddress
-				if m.ip != "" {
-					break
-				}
-				m.ip = netw.GlobalIPv6Address
-				if m.ip != "" {
-					break
-				}
-			}
-		}
-		if m.ip == "" {
-			err = fmt.Errorf("unable to determine IP address for machine %s", m.name)
+		m.runtimeNetworks = NewRuntimeNetworks(inspect.NetworkSettings.Networks)
+		if err = m.loadAddresses(); err != nil {
 			return
 		}
-		m.runtimeNetworks = NewRuntimeNetworks(inspect.NetworkSettings.Networks)
-
 	}
 	return
 }
@@ -550,14 +644,37 @@ func (c *Cluster) Start(machineNames []string) error {
 		return err
 	}
 	if len(machineNames) < 1 {
-		return c.forEachMachine(c.startMachine)
+		if err := c.forEachMachine(c.startMachine); err != nil {
+			return err
+		}
+		return c.afterStart()
 	}
-	return c.forSpecificMachines(c.startMachine, machineNames)
+	return c.StartMachines(machineNames)
 }
 
 // StartMachines starts specific machines(s) in cluster
 func (c *Cluster) StartMachines(machineNames []string) error {
-	return c.forSpecificMachines(c.startMachine, machineNames)
+	if err := c.forSpecificMachines(c.startMachine, machineNames); err != nil {
+		return err
+	}
+	return c.afterStart()
+}
+
+// afterStart refreshes what depends on the machines addresses and ports,
+// which can change when containers restart.
+func (c *Cluster) afterStart() error {
+	// The peers of the started machines need to know about them too.
+	if err := c.updateHosts(); err != nil {
+		return err
+	}
+	// Machines stopped during a key rotation get the new key.
+	if err := c.updateRetiredKeys(); err != nil {
+		return err
+	}
+	if err := c.updateKnownHosts(); err != nil {
+		return err
+	}
+	return c.updateSSHConfig()
 }
 
 func (c *Cluster) stopMachine(machine *Machine, i int) error {
@@ -593,52 +710,6 @@ func (c *Cluster) Stop(machineNames []string) error {
 	return c.forSpecificMachines(c.stopMachine, machineNames)
 }
 
-// io.Writer filter that writes that it receives to writer. Keeps track if it
-// has seen a write matching regexp.
-type matchFilter struct {
-	writer       io.Writer
-	writeMatched bool // whether the filter should write the matched value or not.
-
-	regexp  *regexp.Regexp
-	matched bool
-}
-
-func (f *matchFilter) Write(p []byte) (n int, err error) {
-	if f.regexp.Match(p) {
-		f.matched = true
-		if !f.writeMatched {
-			return len(p), err
-		}
-	}
-	// Write as is if no match
-	return f.writer.Write(p)
-}
-
-// Matches:
-// ssh_exchange_identification: read: Connection reset by peer
-var connectRefused = regexp.MustCompile("(?m)(ssh|kex)_exchange_identification:.+?$")
-
-// execSSH returns true if the command should be tried again.
-func execSSH(args []string) (bool, error) {
-	cmd := exec.Command("ssh", args...)
-
-	refusedFilter := &matchFilter{
-		writer:       os.Stderr,
-		writeMatched: false,
-		regexp:       connectRefused,
-	}
-
-	cmd.SetStdin(os.Stdin)
-	cmd.SetStdout(os.Stdout)
-	cmd.SetStderr(refusedFilter)
-
-	err := cmd.Run()
-	if err != nil && refusedFilter.matched {
-		return true, err
-	}
-	return false, err
-}
-
 func (c *Cluster) machineFromHostname(hostname string) (*Machine, error) {
 	for _, template := range c.spec.Machines {
 		for i := 0; i < template.Count; i++ {
@@ -659,67 +730,20 @@ func mappingFromPort(spec *config.Machine, containerPort int) (*config.PortMappi
 	return nil, fmt.Errorf("unknown containerPort %d", containerPort)
 }
 
-// SSH logs into the name machine with SSH.
-func (c *Cluster) SSH(nodename string, username string, remoteArgs ...string) error {
-	machine, err := c.machineFromHostname(nodename)
+// SSH logs into the name machine with SSH, running an interactive login
+// shell, or the remote command attached to the standard input and outputs.
+// The login shell gets a pseudo terminal only when the standard input is a
+// terminal, a piped script is run as is. Connecting stops when ctx is done.
+func (c *Cluster) SSH(ctx context.Context, nodename string, username string, remoteArgs ...string) error {
+	client, err := c.SSHClient(ctx, nodename, username)
 	if err != nil {
 		return err
 	}
-
-	hostPort, err := machine.HostPort(22)
-	if err != nil {
-		return err
-	}
-	mapping, err := mappingFromPort(machine.spec, 22)
-	if err != nil {
-		return err
-	}
-	remote := "localhost"
-	if mapping.Address != "" {
-		remote = mapping.Address
-	}
-	path, err := expandHomedir(c.spec.Cluster.PrivateKey)
-	if err != nil {
-		return fmt.Errorf("failed to expand private key path: %w", err)
-	}
-	args := []string{
-		"-o", "UserKnownHostsFile=/dev/null",
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "IdentitiesOnly=yes",
-		"-o", "LogLevel=error",
-		"-i", path,
-		"-p", f("%d", hostPort),
-		"-l", username,
-		remote,
-	}
-	args = append(args, remoteArgs...)
-	// If we ssh in a bit too quickly after the container creation, ssh errors out
-	// with:
-	//   ssh_exchange_identification: read: Connection reset by peer
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
-	ticker := time.NewTicker(200 * time.Millisecond)
-	defer ticker.Stop()
-
-	var lastErr error
-	for {
-		select {
-		case <-ctx.Done():
-			if lastErr == nil {
-				return fmt.Errorf("ssh connection failed: %w", ctx.Err())
-			}
-			return fmt.Errorf("ssh connection failed: %s: %w", ctx.Err(), lastErr)
-		case <-ticker.C:
-			retry, lastErr := execSSH(args)
-			if lastErr == nil {
-				return nil
-			}
-
-			if !retry {
-				return lastErr
-			}
-		}
+	defer client.Close()
+	if len(remoteArgs) == 0 && term.IsTerminal(int(os.Stdin.Fd())) {
+		return client.Terminal("", os.Stdin, os.Stdout, os.Stderr)
 	}
+	return client.Run(strings.Join(remoteArgs, " "), os.Stdin, os.Stdout, os.Stderr)
 }
 
 func expandHomedir(path string) (string, error) {
@@ -5,7 +5,6 @@
 package cluster
 
 import (
-	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -15,23 +14,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestMatchFilter(t *testing.T) {
-	const refused = "ssh: connect to host 172.17.0.2 port 22: Connection refused"
-
-	filter := matchFilter{
-		writer: io.Discard,
-		regexp: connectRefused,
-	}
-
-	_, err := filter.Write([]byte("foo\n"))
-	require.NoError(t, err)
-	assert.Equal(t, false, filter.matched)
-
-	_, err = filter.Write([]byte(refused))
-	require.NoError(t, err)
-	assert.Equal(t, false, filter.matched)
-}
-
 func TestNewClusterWithHostPort(t *testing.T) {
 	cluster, err := NewFromYAML([]byte(`cluster:
   name: cluster
@@ -56,18 +38,45 @@ machines:
 	assert.Equal(t, uint16(2222), portMapping.HostPort)
 
 	machine0 := cluster.machine(template.Spec, 0)
-	args0 := cluster.createMachineRunArgs(machine0, machine0.ContainerName(), 0)
+	args0, err := cluster.createMachineRunArgs(machine0, machine0.ContainerName(), 0)
+	require.NoError(t, err)
 	i := indexOf("-p", args0)
 	assert.NotEqual(t, -1, i)
 	assert.Equal(t, "2222:22", args0[i+1])
 
 	machine1 := cluster.machine(template.Spec, 1)
-	args1 := cluster.createMachineRunArgs(machine1, machine1.ContainerName(), 1)
+	args1, err := cluster.createMachineRunArgs(machine1, machine1.ContainerName(), 1)
+	require.NoError(t, err)
 	i = indexOf("-p", args1)
 	assert.NotEqual(t, -1, i)
 	assert.Equal(t, "2223:22", args1[i+1])
 }
 
+func TestCreateMachineRunArgsEnv(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+    env:
+      K0S_VERSION: v1.30.0
+      DEBUG: "1"
+    extraArgs:
+    - --cap-add=NET_ADMIN
+`))
+	require.NoError(t, err)
+
+	machine := cluster.machine(cluster.spec.Machines[0].Spec, 0)
+	args, err := cluster.createMachineRunArgs(machine, machine.ContainerName(), 0)
+	require.NoError(t, err)
+	i := indexOf("-e", args)
+	require.NotEqual(t, -1, i)
+	assert.Equal(t, []string{"-e", "DEBUG=1", "-e", "K0S_VERSION=v1.30.0"}, args[i:i+4])
+	assert.Equal(t, "--cap-add=NET_ADMIN", args[len(args)-1])
+}
+
 func indexOf(element string, array []string) int {
 	for k, v := range array {
 		if element == v {
@@ -124,3 +133,194 @@ func TestCluster_EnsureSSHKeys(t *testing.T) {
 		}
 	})
 }
+
+func TestCreateNetworkArgs(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+  networks:
+  - name: net1
+  - name: net2
+    driver: bridge
+    subnet: 172.30.0.0/16
+    gateway: 172.30.0.1
+    ipv6: true
+    ipv6Subnet: fd00:30::/64
+    ipv6Gateway: fd00:30::1
+    internal: true
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+    networks:
+    - net1
+    - net2
+`))
+	require.NoError(t, err)
+
+	labels := []string{
+		"--label", "io.k0sproject.bootloose.owner=bootloose",
+		"--label", "io.k0sproject.bootloose.cluster=cluster",
+	}
+	assert.Equal(t, labels, cluster.createNetworkArgs(cluster.spec.Cluster.Networks[0]))
+	assert.Equal(t, append(labels,
+		"--driver", "bridge",
+		"--subnet", "172.30.0.0/16",
+		"--gateway", "172.30.0.1",
+		"--ipv6",
+		"--subnet", "fd00:30::/64",
+		"--gateway", "fd00:30::1",
+		"--internal",
+	), cluster.createNetworkArgs(cluster.spec.Cluster.Networks[1]))
+}
+
+func TestCreateMachineRunArgsStaticIP(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+  networks:
+  - name: net1
+    subnet: 172.30.0.0/16
+machines:
+- count: 2
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+    networks:
+    - net1
+    addresses:
+    - network: net1
+      ipv4Address: "172.30.0.{{add 10 .Index}}"
+`))
+	require.NoError(t, err)
+
+	machine := cluster.machine(cluster.spec.Machines[0].Spec, 1)
+	args, err := cluster.createMachineRunArgs(machine, machine.ContainerName(), 1)
+	require.NoError(t, err)
+	i := indexOf("--ip", args)
+	require.NotEqual(t, -1, i)
+	assert.Equal(t, "172.30.0.11", args[i+1])
+}
+
+func TestCreateMachineRunArgsPortAddress(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+    portMappings:
+    - containerPort: 22
+      address: "::1"
+      hostPort: 2222
+    - containerPort: 80
+      address: "[::]"
+    - containerPort: 53
+      address: 127.0.0.1
+      protocol: udp
+`))
+	require.NoError(t, err)
+
+	machine := cluster.machine(cluster.spec.Machines[0].Spec, 0)
+	args, err := cluster.createMachineRunArgs(machine, machine.ContainerName(), 0)
+	require.NoError(t, err)
+	var published []string
+	for i, arg := range args {
+		if arg == "-p" {
+			published = append(published, args[i+1])
+		}
+	}
+	assert.Equal(t, []string{"[::1]:2222:22", "[::]::80", "127.0.0.1::53/udp"}, published)
+
+	_, err = NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+    portMappings:
+    - containerPort: 22
+      address: localhost
+`))
+	assert.ErrorContains(t, err, `portMappings[0]: address "localhost" is not an IP address`)
+}
+
+func TestPlanHostPorts(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 3
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+    portMappings:
+    - containerPort: 22
+      hostPort: 2222
+    - containerPort: 53
+      protocol: udp
+      hostPortRange: 5300-5310
+`))
+	require.NoError(t, err)
+
+	used := map[hostBinding]bool{
+		{port: 2223, protocol: "tcp"}: true,
+		{port: 5300, protocol: "udp"}: true,
+	}
+	available := hostPortAvailable
+	hostPortAvailable = func(b hostBinding) bool { return !used[b] }
+	t.Cleanup(func() { hostPortAvailable = available })
+
+	_, err = cluster.planHostPorts()
+	assert.ErrorContains(t, err, "node1: host port 0.0.0.0:2223/tcp is not available")
+
+	plan, err := cluster.SetAutoHostPorts(true).planHostPorts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]uint16{
+		"cluster-node0": {2222, 5301},
+		"cluster-node1": {0, 5302},
+		"cluster-node2": {2224, 5303},
+	}, plan)
+
+	machine := cluster.machine(cluster.spec.Machines[0].Spec, 1)
+	machine.hostPorts = plan[machine.ContainerName()]
+	args, err := cluster.createMachineRunArgs(machine, machine.ContainerName(), 1)
+	require.NoError(t, err)
+	i := indexOf("-p", args)
+	require.NotEqual(t, -1, i)
+	assert.Equal(t, "22", args[i+1])
+	assert.Equal(t, "5302:53/udp", args[i+3])
+}
+
+func TestHostsBlock(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+  manageHosts: true
+  domain: cluster.local
+machines:
+- count: 3
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+`))
+	require.NoError(t, err)
+
+	machines := cluster.gatherMachinesByCluster()
+	machines[0].runtimeNetworks = []*RuntimeNetwork{
+		{Name: "bridge", IP: "172.17.0.2"},
+		{Name: "net1", IP: "172.30.0.2", IPv6: "fd00::2"},
+	}
+	machines[1].runtimeNetworks = []*RuntimeNetwork{{Name: "net1", IP: "172.30.0.3", IPv6: "fd00::3"}}
+	machines[2].runtimeNetworks = []*RuntimeNetwork{{Name: "net2", IP: "172.31.0.4"}}
+	machines[2].ip = "172.31.0.4"
+
+	assert.Equal(t, `# BEGIN bootloose hosts
+172.17.0.2	node0.cluster.local node0
+172.30.0.2	node0.cluster.local node0
+fd00::2	node0.cluster.local node0
+172.30.0.3	node1.cluster.local node1
+fd00::3	node1.cluster.local node1
+172.31.0.4	node2.cluster.local node2
+# END bootloose hosts
+`, string(cluster.hostsBlock(machines[0], machines)))
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/k0sproject/bootloose/pkg/docker"
+)
+
+// CopyPath is a cp command argument, a host path or a path on machines.
+type CopyPath struct {
+	// Hostname is the machine of a machine path, empty for the selected
+	// machines, eg. with ":/usr/local/bin".
+	Hostname string
+	Path     string
+	// Remote is true for machine paths, written HOSTNAME:PATH.
+	Remote bool
+}
+
+// ParseCopyPath parses a cp command argument. Arguments with a colon not
+// preceded by a slash are machine paths, eg. "node0:/etc/hosts", others are
+// host paths, eg. "./node0:a" or "-" for a tar archive on stdin or stdout.
+func ParseCopyPath(arg string) CopyPath {
+	hostname, p, ok := strings.Cut(arg, ":")
+	if !ok || strings.Contains(hostname, "/") {
+		return CopyPath{Path: arg}
+	}
+	return CopyPath{Hostname: hostname, Path: p, Remote: true}
+}
+
+// CopyTo copies a host file or directory, or a tar archive read from stdin
+// when src is "-", to dest on each of the machines. The parent directory of
+// dest is created when missing. Files keep their mode and belong to root, or
+// keep their owner with archive.
+func (c *Cluster) CopyTo(machines []*Machine, src, dest string, archive bool, stdin io.Reader) error {
+	var archiveFile *os.File
+	if src == "-" && len(machines) > 1 {
+		// The archive is read once and copied to every machine.
+		tmp, err := os.CreateTemp("", "bootloose-cp-*.tar")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if _, err := io.Copy(tmp, stdin); err != nil {
+			return err
+		}
+		archiveFile = tmp
+	}
+	dir := path.Dir(dest)
+	if strings.HasSuffix(dest, "/") || src == "-" {
+		dir = dest
+	}
+	for _, machine := range machines {
+		name := machine.ContainerName()
+		log.Infof("Copying %s to %s:%s ...", src, machine.Hostname(), dest)
+		if err := containerRun(name, "mkdir", "-p", dir); err != nil {
+			return err
+		}
+		if archiveFile != nil {
+			if _, err := archiveFile.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			stdin = archiveFile
+		}
+		if err := docker.Copy(src, name+":"+dest, archive, stdin, nil); err != nil {
+			return fmt.Errorf("%s: %w", machine.Hostname(), err)
+		}
+	}
+	return nil
+}
+
+// CopyFrom copies a file or directory of a machine to dest on the host, or
+// writes it as a tar archive to stdout when dest is "-".
+func (c *Cluster) CopyFrom(machine *Machine, src, dest string, stdout io.Writer) error {
+	log.Infof("Copying %s:%s to %s ...", machine.Hostname(), src, dest)
+	return docker.Copy(machine.ContainerName()+":"+src, dest, false, nil, stdout)
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCopyPath(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected CopyPath
+	}{
+		{"node0:/usr/local/bin/k0s", CopyPath{Hostname: "node0", Path: "/usr/local/bin/k0s", Remote: true}},
+		{":/usr/local/bin/", CopyPath{Path: "/usr/local/bin/", Remote: true}},
+		{"node0:", CopyPath{Hostname: "node0", Remote: true}},
+		{"./k0s", CopyPath{Path: "./k0s"}},
+		{"./node0:a", CopyPath{Path: "./node0:a"}},
+		{"-", CopyPath{Path: "-"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ParseCopyPath(test.arg), test.arg)
+	}
+}
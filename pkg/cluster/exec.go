@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	osexec "os/exec"
+	"strings"
+	"sync"
+
+	"github.com/k0sproject/bootloose/pkg/docker"
+)
+
+// ExecResult is the outcome of a command run on a machine.
+type ExecResult struct {
+	Machine  string `json:"machine"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exitCode"`
+	// Error is set when the command couldn't be run at all.
+	Error string `json:"error,omitempty"`
+}
+
+// ExecOptions customizes how commands are run on the machines.
+type ExecOptions struct {
+	// Parallel is the number of machines the command runs on at once.
+	// Defaults to all of them.
+	Parallel int
+	// Stdout and Stderr receive the output of the machines as it comes,
+	// each line prefixed with the machine hostname. It is collected in the
+	// results either way.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Color colours the prefixes, each machine in its own colour.
+	Color bool
+}
+
+// ANSI colours of the output prefixes.
+var execColors = []string{"36", "33", "35", "32", "34", "91", "96", "93", "95", "92"}
+
+// prefixWriter writes complete lines prefixed, the writers of all machines
+// sharing a lock so their lines don't mix.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+string(line))
+	return err
+}
+
+// flush writes the last line when it doesn't end with a newline.
+func (p *prefixWriter) flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+// execPrefixes returns the output prefix of each machine, the hostnames
+// padded to the same width.
+func execPrefixes(machines []*Machine, color bool) []string {
+	width := 0
+	for _, machine := range machines {
+		width = max(width, len(machine.Hostname()))
+	}
+	prefixes := make([]string, len(machines))
+	for i, machine := range machines {
+		prefix := f("%-*s |", width, machine.Hostname())
+		if color {
+			prefix = f("\x1b[%sm%s\x1b[0m", execColors[i%len(execColors)], prefix)
+		}
+		prefixes[i] = prefix + " "
+	}
+	return prefixes
+}
+
+// Exec runs a command with docker exec on the machines, without terminal,
+// and returns the results in the order of the machines.
+func (c *Cluster) Exec(machines []*Machine, command []string, opts ExecOptions) []ExecResult {
+	parallel := opts.Parallel
+	if parallel <= 0 || parallel > len(machines) {
+		parallel = len(machines)
+	}
+	prefixes := execPrefixes(machines, opts.Color)
+	results := make([]ExecResult, len(machines))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	for i, machine := range machines {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			var stdout, stderr *prefixWriter
+			if opts.Stdout != nil {
+				stdout = &prefixWriter{mu: &mu, w: opts.Stdout, prefix: prefixes[i]}
+			}
+			if opts.Stderr != nil {
+				stderr = &prefixWriter{mu: &mu, w: opts.Stderr, prefix: prefixes[i]}
+			}
+			results[i] = execMachine(machine, command, stdout, stderr)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// execMachine runs a command on a machine, collecting its output and
+// streaming it to stdout and stderr when not nil.
+func execMachine(machine *Machine, command []string, stdout, stderr *prefixWriter) ExecResult {
+	result := ExecResult{Machine: machine.Hostname()}
+	var outBuf, errBuf bytes.Buffer
+	cmd := docker.ContainerCmder(machine.ContainerName()).Command(command[0], command[1:]...)
+	if stdout != nil {
+		cmd.SetStdout(io.MultiWriter(&outBuf, stdout))
+	} else {
+		cmd.SetStdout(&outBuf)
+	}
+	if stderr != nil {
+		cmd.SetStderr(io.MultiWriter(&errBuf, stderr))
+	} else {
+		cmd.SetStderr(&errBuf)
+	}
+	err := cmd.Run()
+	if stdout != nil {
+		_ = stdout.flush()
+	}
+	if stderr != nil {
+		_ = stderr.flush()
+	}
+	result.Stdout, result.Stderr = outBuf.String(), errBuf.String()
+	var exitErr *osexec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ExecError reports the machines a command failed on.
+type ExecError struct {
+	Failed []ExecResult
+	// exit status of the bootloose command
+	status int
+}
+
+func (e *ExecError) Error() string {
+	failures := make([]string, len(e.Failed))
+	for i, result := range e.Failed {
+		if result.Error != "" {
+			failures[i] = f("%s (%s)", result.Machine, result.Error)
+		} else {
+			failures[i] = f("%s (exit status %d)", result.Machine, result.ExitCode)
+		}
+	}
+	return "command failed on " + strings.Join(failures, ", ")
+}
+
+// ExecErr returns an *ExecError when the command failed on any machine. Its
+// exit status is the one of the command when run on a single machine, 1
+// otherwise.
+func ExecErr(results []ExecResult) error {
+	err := &ExecError{status: 1}
+	for _, result := range results {
+		if result.ExitCode != 0 {
+			err.Failed = append(err.Failed, result)
+		}
+	}
+	if len(err.Failed) == 0 {
+		return nil
+	}
+	if len(results) == 1 && results[0].ExitCode > 0 {
+		err.status = results[0].ExitCode
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &prefixWriter{mu: &sync.Mutex{}, w: &buf, prefix: "node0 | "}
+	_, _ = w.Write([]byte("hello\nwor"))
+	_, _ = w.Write([]byte("ld\nno newline"))
+	assert.Equal(t, "node0 | hello\nnode0 | world\n", buf.String())
+	assert.NoError(t, w.flush())
+	assert.Equal(t, "node0 | hello\nnode0 | world\nnode0 | no newline\n", buf.String())
+}
+
+func TestExecPrefixes(t *testing.T) {
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{Name: "cluster"}}}
+	spec := &config.Machine{Name: "node%d"}
+	machines := []*Machine{c.machine(spec, 0), c.machine(&config.Machine{Name: "worker%d"}, 10)}
+	assert.Equal(t, []string{"node0    | ", "worker10 | "}, execPrefixes(machines, false))
+	assert.Equal(t, "\x1b[36mnode0    |\x1b[0m ", execPrefixes(machines, true)[0])
+}
+
+func TestExecErr(t *testing.T) {
+	assert.NoError(t, ExecErr([]ExecResult{{Machine: "node0"}}))
+
+	err := ExecErr([]ExecResult{{Machine: "node0", ExitCode: 3}})
+	assert.EqualError(t, err, "command failed on node0 (exit status 3)")
+	status, ok := ExitStatus(err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, status)
+
+	err = ExecErr([]ExecResult{{Machine: "node0"}, {Machine: "node1", ExitCode: 2}, {Machine: "node2", ExitCode: -1, Error: "no such container"}})
+	assert.EqualError(t, err, "command failed on node1 (exit status 2), node2 (no such container)")
+	status, _ = ExitStatus(err)
+	assert.Equal(t, 1, status)
+}
@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/k0sproject/bootloose/pkg/docker"
+	"github.com/k0sproject/bootloose/pkg/exec"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetNetworkHelper sets the image of a helper container used to run tc in the
+// network namespace of the machines when shaping their traffic. By default tc
+// is run in the machines themselves, which requires it to be installed and
+// the machines to be privileged.
+func (c *Cluster) SetNetworkHelper(image string) *Cluster {
+	c.networkHelper = image
+	return c
+}
+
+// machineNetworks returns the networks the machine is attached to when
+// created.
+func machineNetworks(machine *Machine) []string {
+	if len(machine.spec.Networks) == 0 {
+		return []string{"bridge"}
+	}
+	return machine.spec.Networks
+}
+
+// connectedNetworks returns the networks the machine is currently connected
+// to.
+func connectedNetworks(machine *Machine) ([]string, error) {
+	res, err := docker.Inspect(machine.ContainerName(), "{{range $name, $_ := .NetworkSettings.Networks}}{{$name}} {{end}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", machine.ContainerName(), err)
+	}
+	return strings.Fields(strings.Trim(strings.Join(res, " "), "'")), nil
+}
+
+func (c *Cluster) machinesFromHostnames(hostnames []string) ([]*Machine, error) {
+	if len(hostnames) == 0 {
+		return c.gatherMachinesByCluster(), nil
+	}
+	machines := make([]*Machine, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		machine, err := c.machineFromHostname(hostname)
+		if err != nil {
+			return nil, err
+		}
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+// Partition disconnects the machines from their networks, or only from
+// network when it isn't empty. Heal connects them back.
+func (c *Cluster) Partition(hostnames []string, network string) error {
+	machines, err := c.machinesFromHostnames(hostnames)
+	if err != nil {
+		return err
+	}
+	for _, machine := range machines {
+		networks := machineNetworks(machine)
+		if network != "" {
+			if !slices.Contains(networks, network) {
+				return fmt.Errorf("%s is not attached to the %s network", machine.Hostname(), network)
+			}
+			networks = []string{network}
+		}
+		connected, err := connectedNetworks(machine)
+		if err != nil {
+			return err
+		}
+		for _, n := range networks {
+			if !slices.Contains(connected, n) {
+				continue
+			}
+			log.Infof("Disconnecting %s from the %s network...", machine.ContainerName(), n)
+			if err := docker.DisconnectNetwork(machine.ContainerName(), n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Shape applies traffic control to the network interfaces of the machines.
+// When iface is empty, all the interfaces but the loopback are shaped.
+func (c *Cluster) Shape(hostnames []string, shaping config.Shaping, iface string) error {
+	if err := shaping.Validate(); err != nil {
+		return err
+	}
+	machines, err := c.machinesFromHostnames(hostnames)
+	if err != nil {
+		return err
+	}
+	devices := "$(ls /sys/class/net | grep -v '^lo$')"
+	if iface != "" {
+		devices = shellQuote(iface)
+	}
+	script := f("set -e; for dev in %s; do tc qdisc replace dev \"$dev\" root %s; done",
+		devices, strings.Join(shaping.NetemArgs(), " "))
+	for _, machine := range machines {
+		log.Infof("Shaping the traffic of %s: %s", machine.ContainerName(), strings.Join(shaping.NetemArgs()[1:], " "))
+		if err := c.runNetworkScript(machine, script); err != nil {
+			return fmt.Errorf("failed to shape the traffic of %s: %w", machine.Hostname(), err)
+		}
+	}
+	return nil
+}
+
+// Heal connects the machines back to their networks and removes any traffic
+// shaping. All the machines are healed when hostnames is empty.
+func (c *Cluster) Heal(hostnames []string) error {
+	machines, err := c.machinesFromHostnames(hostnames)
+	if err != nil {
+		return err
+	}
+	reconnected := false
+	for _, machine := range machines {
+		if !machine.IsCreated() {
+			continue
+		}
+		connected, err := connectedNetworks(machine)
+		if err != nil {
+			return err
+		}
+		for _, network := range machineNetworks(machine) {
+			if slices.Contains(connected, network) {
+				continue
+			}
+			if err := c.connectNetwork(machine, network); err != nil {
+				return err
+			}
+			reconnected = true
+		}
+		if !machine.IsStarted() {
+			log.Infof("Machine %s is not started, skipping traffic shaping removal...", machine.ContainerName())
+			continue
+		}
+		script := "for dev in $(ls /sys/class/net); do tc qdisc del dev \"$dev\" root 2>/dev/null || true; done"
+		if err := c.runNetworkScript(machine, script); err != nil {
+			return fmt.Errorf("failed to remove the traffic shaping of %s: %w", machine.Hostname(), err)
+		}
+	}
+	if !reconnected {
+		return nil
+	}
+	// The reconnected machines may have new addresses.
+	return c.updateHosts()
+}
+
+// ApplyFaults applies the faults described in the cluster configuration.
+func (c *Cluster) ApplyFaults() error {
+	if len(c.spec.Faults) == 0 {
+		log.Infof("No faults described in the configuration")
+		return nil
+	}
+	for _, fault := range c.spec.Faults {
+		if fault.Partition {
+			if err := c.Partition(fault.Machines, fault.Network); err != nil {
+				return err
+			}
+		}
+		if fault.Shape != nil {
+			if err := c.Shape(fault.Machines, *fault.Shape, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RevertFaults heals the machines affected by the faults described in the
+// cluster configuration.
+func (c *Cluster) RevertFaults() error {
+	var hostnames []string
+	for _, fault := range c.spec.Faults {
+		for _, hostname := range fault.Machines {
+			if !slices.Contains(hostnames, hostname) {
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+	if len(hostnames) == 0 {
+		log.Infof("No faults described in the configuration")
+		return nil
+	}
+	return c.Heal(hostnames)
+}
+
+// runNetworkScript runs script in the machine, or in the network helper
+// container sharing the machine network namespace.
+func (c *Cluster) runNetworkScript(machine *Machine, script string) error {
+	if c.networkHelper == "" {
+		return containerRunShell(machine.ContainerName(), script)
+	}
+	cmd := exec.Command("docker", "run", "--rm",
+		"--network", "container:"+machine.ContainerName(),
+		"--cap-add", "NET_ADMIN",
+		c.networkHelper,
+		"/bin/sh", "-c", script,
+	)
+	output, err := exec.CombinedOutputLines(cmd)
+	if err != nil {
+		for _, line := range output {
+			log.WithField("machine", machine.ContainerName()).Error(line)
+		}
+	}
+	return err
+}
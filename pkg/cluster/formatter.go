@@ -52,6 +52,7 @@ type MachineStatus struct {
 	Image           string            `json:"image"`
 	Command         string            `json:"cmd"`
 	IP              string            `json:"ip"`
+	IPv6            string            `json:"ipv6,omitempty"`
 	RuntimeNetworks []*RuntimeNetwork `json:"runtimeNetworks,omitempty"`
 }
 
@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/k0sproject/bootloose/pkg/docker"
+	"github.com/k0sproject/bootloose/pkg/exec"
+)
+
+// DefaultKnownHosts is the known_hosts file the machines host keys are
+// written to without a configured path.
+const DefaultKnownHosts = "~/.ssh/bootloose_known_hosts"
+
+// hostKeyPath is the machines host key, generated by bootloose so that each
+// machine has its own rather than the one baked in the image.
+const hostKeyPath = "/etc/ssh/ssh_host_ed25519_key"
+
+// hostKeyAlgorithm is the algorithm of the host key at hostKeyPath, the only
+// one accepted from the machines for their keys to match known_hosts.
+const hostKeyAlgorithm = ssh.KeyAlgoED25519
+
+// generateEd25519Key returns a new Ed25519 key pair, the private key PEM
+// encoded and the public key in the authorized_keys format.
+func generateEd25519Key() ([]byte, []byte, error) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate new Ed25519 key: %w", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert Ed25519 public key into SSH public key: %w", err)
+	}
+	privPEM, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert Ed25519 private key into PEM block: %w", err)
+	}
+	return pem.EncodeToMemory(privPEM), ssh.MarshalAuthorizedKey(sshPub), nil
+}
+
+// KnownHostsPath returns the absolute path of the known_hosts file of the
+// cluster, the configured one or DefaultKnownHosts.
+func (c *Cluster) KnownHostsPath() (string, error) {
+	path := c.spec.Cluster.KnownHosts
+	if path == "" {
+		path = DefaultKnownHosts
+	}
+	path, err := expandHomedir(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand known_hosts path: %w", err)
+	}
+	return filepath.Abs(path)
+}
+
+// injectHostKey copies a new host key into a created machine. It is done
+// before starting the machine for sshd to pick it up.
+func injectHostKey(machine *Machine) error {
+	priv, pub, err := generateEd25519Key()
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "bootloose-hostkey")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Base(hostKeyPath)
+	if err := os.WriteFile(filepath.Join(dir, name), priv, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".pub"), pub, 0o644); err != nil {
+		return err
+	}
+	// docker cp creates the files as root, keeping their mode.
+	return docker.CopyTo(dir+"/.", machine.ContainerName(), filepath.Dir(hostKeyPath))
+}
+
+// hostKey returns the host key of a running machine, read through docker.
+func hostKey(machine *Machine) (ssh.PublicKey, error) {
+	cmd := docker.ContainerCmder(machine.ContainerName()).Command("cat", hostKeyPath+".pub")
+	lines, err := exec.CombinedOutputLines(cmd)
+	if err != nil || len(lines) == 0 {
+		return nil, fmt.Errorf("%s: failed to read the host key", machine.ContainerName())
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(lines[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse the host key: %w", machine.ContainerName(), err)
+	}
+	return key, nil
+}
+
+// knownHostsNames returns the names a running machine is known as: its
+// mapped SSH port, when there is one, its container name and its address, if
+// any.
+func knownHostsNames(machine *Machine) ([]string, error) {
+	var names []string
+	if _, err := mappingFromPort(machine.spec, 22); err == nil {
+		host, port, err := sshEndpoint(machine)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, knownhosts.Normalize(net.JoinHostPort(host, strconv.Itoa(port))))
+	}
+	names = append(names, machine.ContainerName())
+	if err := machine.loadAddresses(); err == nil {
+		names = append(names, machine.ip)
+	}
+	return names, nil
+}
+
+// updateKnownHosts writes the host keys of the running machines to the
+// cluster block of the known_hosts file, their SSH ports possibly changing
+// when the machines restart.
+func (c *Cluster) updateKnownHosts() error {
+	path, err := c.KnownHostsPath()
+	if err != nil {
+		return err
+	}
+	var block bytes.Buffer
+	err = c.forEachMachine(func(machine *Machine, _ int) error {
+		if !machine.IsCreated() || !machine.IsStarted() {
+			return nil
+		}
+		key, err := hostKey(machine)
+		if err != nil {
+			log.Warnf("%v, it won't be in %s", err, path)
+			return nil
+		}
+		names, err := knownHostsNames(machine)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(&block, knownhosts.Line(names, key))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.updateSSHConfigFile(path, block.Bytes())
+}
+
+// removeKnownHosts removes the cluster block of the known_hosts file.
+func (c *Cluster) removeKnownHosts() error {
+	path, err := c.KnownHostsPath()
+	if err != nil {
+		return err
+	}
+	return c.updateSSHConfigFile(path, nil)
+}
+
+// hostKeyCallback checks the machines host keys against the known_hosts file.
+// A missing file, eg. for clusters started before bootloose recorded host
+// keys, knows no host.
+func (c *Cluster) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	path, err := c.KnownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		files = append(files, path)
+	}
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the machines host keys: %w", err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return fmt.Errorf("no host key known for %s in %s, run 'bootloose start' to record it", hostname, path)
+		}
+		if errors.As(err, &keyErr) {
+			return fmt.Errorf("host key of %s does not match the one in %s: %w", hostname, path, err)
+		}
+		return err
+	}, nil
+}
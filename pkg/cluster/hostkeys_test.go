@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestHostKeyCallback(t *testing.T) {
+	priv, pub, err := generateEd25519Key()
+	require.NoError(t, err)
+	_, err = ssh.ParsePrivateKey(priv)
+	require.NoError(t, err)
+	key, _, _, _, err := ssh.ParseAuthorizedKey(pub)
+	require.NoError(t, err)
+	_, otherPub, err := generateEd25519Key()
+	require.NoError(t, err)
+	other, _, _, _, err := ssh.ParseAuthorizedKey(otherPub)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{Name: "cluster", KnownHosts: path}}}
+	block := knownhosts.Line([]string{"[localhost]:2222", "cluster-node0", "172.17.0.2"}, key) + "\n"
+	require.NoError(t, c.updateSSHConfigFile(path, []byte(block)))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "[localhost]:2222,cluster-node0,172.17.0.2 ssh-ed25519 ")
+
+	callback, err := c.hostKeyCallback()
+	require.NoError(t, err)
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}
+	assert.NoError(t, callback("localhost:2222", addr, key))
+	assert.ErrorContains(t, callback("localhost:2222", addr, other), "does not match")
+	assert.ErrorContains(t, callback("localhost:2223", addr, key), "no host key known for localhost:2223")
+
+	c.spec.Cluster.KnownHosts = filepath.Join(t.TempDir(), "missing")
+	callback, err = c.hostKeyCallback()
+	require.NoError(t, err)
+	assert.ErrorContains(t, callback("localhost:2222", addr, key), "no host key known for localhost:2222 in "+c.spec.Cluster.KnownHosts+", run 'bootloose start' to record it")
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/k0sproject/bootloose/pkg/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+// hostBinding is a host address, port and protocol a machine port is
+// published on.
+type hostBinding struct {
+	address  string
+	port     int
+	protocol string
+}
+
+func (b hostBinding) String() string {
+	address := b.address
+	if address == "" {
+		address = "0.0.0.0"
+	}
+	return f("%s/%s", net.JoinHostPort(address, f("%d", b.port)), b.protocol)
+}
+
+// hostPortAvailable checks whether the host binding can be used, by binding it.
+var hostPortAvailable = func(b hostBinding) bool {
+	address := net.JoinHostPort(b.address, f("%d", b.port))
+	if b.protocol == "udp" {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return false
+	}
+	_ = listener.Close()
+	return true
+}
+
+// SetAutoHostPorts makes Create let docker allocate a random host port when a
+// planned host port is not available, instead of failing.
+func (c *Cluster) SetAutoHostPorts(auto bool) *Cluster {
+	c.autoHostPorts = auto
+	return c
+}
+
+// remoteDocker returns true when the docker daemon doesn't run on this host,
+// in which case host ports can't be checked locally.
+func remoteDocker() bool {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		return false
+	}
+	u, err := url.Parse(host)
+	return err != nil || (u.Scheme != "unix" && u.Scheme != "npipe")
+}
+
+// planHostPorts checks that the host ports of the machines about to be created
+// are available and picks the ports of the mappings declaring a host port
+// range. The returned map gives, for each container name, the host port of
+// each port mapping, 0 letting docker allocate one.
+func (c *Cluster) planHostPorts() (map[string][]uint16, error) {
+	check := hostPortAvailable
+	if remoteDocker() {
+		log.Warnf("DOCKER_HOST is not local, skipping the host ports availability check")
+		check = func(hostBinding) bool { return true }
+	}
+
+	plan := map[string][]uint16{}
+	reserved := map[hostBinding]string{}
+	var errs []error
+	err := c.forEachMachine(func(machine *Machine, i int) error {
+		if machine.IsCreated() {
+			return nil
+		}
+		ports := make([]uint16, len(machine.spec.PortMappings))
+		for k, mapping := range machine.spec.PortMappings {
+			binding := hostBinding{address: mapping.Address, protocol: mapping.Protocol}
+			binding.address = strings.TrimSuffix(strings.TrimPrefix(binding.address, "["), "]")
+			if binding.protocol == "" {
+				binding.protocol = "tcp"
+			}
+			free := func(port int) bool {
+				binding.port = port
+				_, taken := reserved[binding]
+				return !taken && check(binding)
+			}
+
+			switch {
+			case mapping.HostPortRange != "":
+				first, last, err := mapping.HostPorts()
+				if err != nil {
+					return err
+				}
+				binding.port = 0
+				for port := int(first); port <= int(last); port++ {
+					if free(port) {
+						break
+					}
+					binding.port = 0
+				}
+				if binding.port == 0 {
+					errs = append(errs, fmt.Errorf("%s: no available host port in range %s for container port %d", machine.Hostname(), mapping.HostPortRange, mapping.ContainerPort))
+					continue
+				}
+			case mapping.HostPort != 0:
+				port := int(mapping.HostPort) + i
+				if port > 65535 {
+					errs = append(errs, fmt.Errorf("%s: host port %d is out of range", machine.Hostname(), port))
+					continue
+				}
+				if !free(port) {
+					if !c.autoHostPorts {
+						errs = append(errs, fmt.Errorf("%s: host port %s is not available", machine.Hostname(), binding))
+						continue
+					}
+					log.Warnf("%s: host port %s is not available, letting docker allocate one", machine.Hostname(), binding)
+					continue
+				}
+			default:
+				continue
+			}
+			reserved[binding] = machine.Hostname()
+			ports[k] = uint16(binding.port)
+		}
+		plan[machine.ContainerName()] = ports
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("host ports preflight failed, free the ports or use --auto-ports: %w", errors.Join(errs...))
+	}
+	return plan, nil
+}
+
+// reportHostPorts logs the host ports the machine ports are published on.
+func (c *Cluster) reportHostPorts() error {
+	return c.forEachMachine(func(machine *Machine, _ int) error {
+		if !machine.IsCreated() {
+			return nil
+		}
+		var ports nat.PortMap
+		if err := docker.InspectObject(machine.ContainerName(), ".NetworkSettings.Ports", &ports); err != nil {
+			return err
+		}
+		var mappings []string
+		for port, bindings := range ports {
+			for _, binding := range bindings {
+				mappings = append(mappings, f("%s->%s", net.JoinHostPort(binding.HostIP, binding.HostPort), port))
+			}
+		}
+		sort.Strings(mappings)
+		if len(mappings) > 0 {
+			log.Infof("Ports of %s: %s", machine.ContainerName(), strings.Join(mappings, ", "))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	hostsBegin = "# BEGIN bootloose hosts"
+	hostsEnd   = "# END bootloose hosts"
+)
+
+// hostsScript replaces the bootloose block of /etc/hosts. The file is a bind
+// mount of the container, so it is rewritten in place rather than replaced.
+const hostsScript = `set -e
+hosts=$(sed '/^` + hostsBegin + `$/,/^` + hostsEnd + `$/d' /etc/hosts)
+printf '%%s\n' "$hosts" > /etc/hosts
+cat >> /etc/hosts <<'__EOF'
+%s__EOF`
+
+// hostNames returns the names a machine is known as in /etc/hosts.
+func (c *Cluster) hostNames(machine *Machine) string {
+	if c.spec.Cluster.Domain == "" {
+		return machine.Hostname()
+	}
+	return f("%s.%s %s", machine.Hostname(), c.spec.Cluster.Domain, machine.Hostname())
+}
+
+// peerAddresses returns the addresses machine can reach peer at, preferring the
+// networks they share and falling back to the peer main address.
+func peerAddresses(machine, peer *Machine) []string {
+	var addresses []string
+	for _, netw := range peer.runtimeNetworks {
+		for _, own := range machine.runtimeNetworks {
+			if own.Name != netw.Name {
+				continue
+			}
+			if netw.IP != "" {
+				addresses = append(addresses, netw.IP)
+			}
+			if netw.IPv6 != "" {
+				addresses = append(addresses, netw.IPv6)
+			}
+		}
+	}
+	if len(addresses) == 0 && peer.ip != "" {
+		addresses = append(addresses, peer.ip)
+	}
+	return addresses
+}
+
+// hostsBlock returns the /etc/hosts entries of the cluster machines, as seen
+// from machine.
+func (c *Cluster) hostsBlock(machine *Machine, machines []*Machine) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, hostsBegin)
+	for _, peer := range machines {
+		for _, address := range peerAddresses(machine, peer) {
+			fmt.Fprintf(&buf, "%s\t%s\n", address, c.hostNames(peer))
+		}
+	}
+	fmt.Fprintln(&buf, hostsEnd)
+	return buf.Bytes()
+}
+
+// updateHosts writes the hostnames and addresses of the running machines into
+// the /etc/hosts file of each of them, when the cluster manages hosts.
+func (c *Cluster) updateHosts() error {
+	if !c.spec.Cluster.ManageHosts {
+		return nil
+	}
+	var running []*Machine
+	for _, machine := range c.gatherMachinesByCluster() {
+		if !machine.IsCreated() || !machine.IsStarted() {
+			continue
+		}
+		if err := machine.loadAddresses(); err != nil {
+			return err
+		}
+		running = append(running, machine)
+	}
+	for _, machine := range running {
+		log.Infof("Updating /etc/hosts of %s ...", machine.ContainerName())
+		if err := containerRunShell(machine.ContainerName(), f(hostsScript, c.hostsBlock(machine, running))); err != nil {
+			return fmt.Errorf("failed to update /etc/hosts of %s: %w", machine.ContainerName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Ansible connection types of the inventory hosts.
+const (
+	// InventorySSH connects to the machines with SSH through their mapped
+	// port 22.
+	InventorySSH = "ssh"
+	// InventoryDocker connects to the machines with docker exec.
+	InventoryDocker = "docker"
+)
+
+// Inventory is an Ansible inventory of the cluster machines. Hosts are named
+// after the machine containers.
+type Inventory struct {
+	// Groups are the hosts of each group, one per machine template and per
+	// machine label.
+	Groups map[string][]string
+	// HostVars are the variables of each host.
+	HostVars map[string]map[string]string
+
+	// template group of each host
+	templates map[string]string
+}
+
+var invalidGroupChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// inventoryGroup turns a name into a valid Ansible group name.
+func inventoryGroup(name string) string {
+	group := strings.Trim(invalidGroupChars.ReplaceAllString(name, "_"), "_")
+	if group == "" || (group[0] >= '0' && group[0] <= '9') {
+		group = "_" + group
+	}
+	return group
+}
+
+// templateGroup is the group of the machines of a template, named after the
+// template machine name without its index, eg. "worker" for "worker%d".
+func templateGroup(name string) string {
+	return inventoryGroup(strings.ReplaceAll(name, "%d", ""))
+}
+
+// Inventory returns an Ansible inventory of the running machines, connecting
+// to them with the given connection type.
+func (c *Cluster) Inventory(connection string) (*Inventory, error) {
+	if connection != InventorySSH && connection != InventoryDocker {
+		return nil, fmt.Errorf("unknown connection type %q, should be %s or %s", connection, InventorySSH, InventoryDocker)
+	}
+	identity, err := c.privateKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	knownHosts, err := c.KnownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{
+		Groups:    map[string][]string{},
+		HostVars:  map[string]map[string]string{},
+		templates: map[string]string{},
+	}
+	err = c.forEachMachine(func(machine *Machine, _ int) error {
+		if !machine.IsCreated() || !machine.IsStarted() {
+			return nil
+		}
+		vars := map[string]string{"bootloose_hostname": machine.Hostname()}
+		if connection == InventoryDocker {
+			vars["ansible_connection"] = "docker"
+		} else {
+			hostName, port, err := sshEndpoint(machine)
+			if err != nil {
+				return err
+			}
+			vars["ansible_host"] = hostName
+			vars["ansible_port"] = f("%d", port)
+			vars["ansible_user"] = sshConfigUser
+			if identity != "" {
+				vars["ansible_ssh_private_key_file"] = identity
+			}
+			vars["ansible_ssh_common_args"] = f("-o StrictHostKeyChecking=yes -o UserKnownHostsFile=%s -o HostKeyAlgorithms=%s", knownHosts, hostKeyAlgorithm)
+		}
+		inventory.add(machine, vars)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+// add adds a machine to its template and label groups.
+func (i *Inventory) add(machine *Machine, vars map[string]string) {
+	host := machine.ContainerName()
+	i.HostVars[host] = vars
+	i.templates[host] = templateGroup(machine.spec.Name)
+	groups := []string{i.templates[host]}
+	for key, value := range machine.spec.Labels {
+		groups = append(groups, inventoryGroup(key+"_"+value))
+	}
+	for _, group := range groups {
+		i.Groups[group] = append(i.Groups[group], host)
+	}
+}
+
+func (i *Inventory) groupNames() []string {
+	names := make([]string, 0, len(i.Groups))
+	for name := range i.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteINI writes the inventory in the INI format. Host variables are given
+// in the template groups, every host belonging to exactly one of them.
+func (i *Inventory) WriteINI(w io.Writer) error {
+	for n, group := range i.groupNames() {
+		if n > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "[%s]\n", group)
+		for _, host := range i.Groups[group] {
+			line := host
+			if vars := i.HostVars[host]; i.templates[host] == group {
+				for _, key := range sortedKeys(vars) {
+					line += f(" %s=%s", key, iniValue(vars[key]))
+				}
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// iniValue quotes values containing spaces.
+func iniValue(value string) string {
+	if strings.ContainsAny(value, " \t\"'") {
+		return f("%q", value)
+	}
+	return value
+}
+
+// yamlInventory is the structure of a YAML inventory.
+type yamlInventory struct {
+	All struct {
+		Children map[string]yamlGroup `json:"children"`
+	} `json:"all"`
+}
+
+type yamlGroup struct {
+	Hosts map[string]map[string]string `json:"hosts"`
+}
+
+// WriteYAML writes the inventory in the YAML format. As with WriteINI, host
+// variables are given in the template groups.
+func (i *Inventory) WriteYAML(w io.Writer) error {
+	var inventory yamlInventory
+	inventory.All.Children = map[string]yamlGroup{}
+	for group, hosts := range i.Groups {
+		g := yamlGroup{Hosts: map[string]map[string]string{}}
+		for _, host := range hosts {
+			g.Hosts[host] = map[string]string{}
+			if i.templates[host] == group {
+				g.Hosts[host] = i.HostVars[host]
+			}
+		}
+		inventory.All.Children[group] = g
+	}
+	data, err := yaml.Marshal(inventory)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteJSON writes the inventory in the format of Ansible dynamic inventory
+// scripts called with --list.
+func (i *Inventory) WriteJSON(w io.Writer) error {
+	inventory := map[string]interface{}{
+		"_meta": map[string]interface{}{"hostvars": i.HostVars},
+		"all":   map[string]interface{}{"children": i.groupNames()},
+	}
+	for group, hosts := range i.Groups {
+		inventory[group] = map[string]interface{}{"hosts": hosts}
+	}
+	return writeJSON(w, inventory)
+}
+
+// WriteHostJSON writes the variables of a host in the format of Ansible
+// dynamic inventory scripts called with --host. Unknown hosts have no
+// variables.
+func (i *Inventory) WriteHostJSON(w io.Writer, host string) error {
+	vars := i.HostVars[host]
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	return writeJSON(w, vars)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
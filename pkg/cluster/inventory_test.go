@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: controller%d
+    labels:
+      role: control-plane
+- count: 2
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: worker-%d
+`))
+	require.NoError(t, err)
+
+	inventory := &Inventory{Groups: map[string][]string{}, HostVars: map[string]map[string]string{}, templates: map[string]string{}}
+	err = cluster.forEachMachine(func(machine *Machine, i int) error {
+		inventory.add(machine, map[string]string{"ansible_connection": "docker", "bootloose_hostname": machine.Hostname()})
+		return nil
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, inventory.WriteINI(&buf))
+	assert.Equal(t, `[controller]
+cluster-controller0 ansible_connection=docker bootloose_hostname=controller0
+
+[role_control_plane]
+cluster-controller0
+
+[worker]
+cluster-worker-0 ansible_connection=docker bootloose_hostname=worker-0
+cluster-worker-1 ansible_connection=docker bootloose_hostname=worker-1
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, inventory.WriteYAML(&buf))
+	assert.Equal(t, `all:
+  children:
+    controller:
+      hosts:
+        cluster-controller0:
+          ansible_connection: docker
+          bootloose_hostname: controller0
+    role_control_plane:
+      hosts:
+        cluster-controller0: {}
+    worker:
+      hosts:
+        cluster-worker-0:
+          ansible_connection: docker
+          bootloose_hostname: worker-0
+        cluster-worker-1:
+          ansible_connection: docker
+          bootloose_hostname: worker-1
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, inventory.WriteJSON(&buf))
+	var list map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &list))
+	assert.JSONEq(t, `{"hosts": ["cluster-worker-0", "cluster-worker-1"]}`, string(list["worker"]))
+	assert.JSONEq(t, `{"children": ["controller", "role_control_plane", "worker"]}`, string(list["all"]))
+
+	buf.Reset()
+	require.NoError(t, inventory.WriteHostJSON(&buf, "cluster-worker-1"))
+	assert.JSONEq(t, `{"ansible_connection": "docker", "bootloose_hostname": "worker-1"}`, buf.String())
+}
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// K0sctlRoleLabel is the machine label giving the k0s role of the machines.
+// Without it, the role is guessed from the template machine name. Machines
+// labelled with the "none" role, eg. load balancers, are left out.
+const K0sctlRoleLabel = "role"
+
+const k0sctlNoRole = "none"
+
+var k0sctlRoles = []string{"controller", "worker", "controller+worker", "single"}
+
+// K0sctlOptions customizes the exported k0sctl configuration.
+type K0sctlOptions struct {
+	// Name is the name of the k0sctl cluster. Defaults to the cluster name.
+	Name string
+	// K0sVersion is the version of k0s to install. k0sctl picks the latest
+	// version when empty.
+	K0sVersion string
+	// PrivateAddresses sets the private address of the machines to their
+	// container address.
+	PrivateAddresses bool
+	// Bastion is the hostname of the machine k0sctl connects through to reach
+	// the other machines at their private container addresses. When empty,
+	// k0sctl connects to each machine through its mapped SSH port.
+	Bastion string
+}
+
+type k0sctlSSH struct {
+	Address string     `json:"address"`
+	Port    int        `json:"port"`
+	User    string     `json:"user"`
+	KeyPath string     `json:"keyPath,omitempty"`
+	Bastion *k0sctlSSH `json:"bastion,omitempty"`
+}
+
+type k0sctlHost struct {
+	Role           string    `json:"role"`
+	SSH            k0sctlSSH `json:"ssh"`
+	Hostname       string    `json:"hostname,omitempty"`
+	PrivateAddress string    `json:"privateAddress,omitempty"`
+}
+
+type k0sctlConfig struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Hosts []k0sctlHost `json:"hosts"`
+		K0s   *k0sctlK0s   `json:"k0s,omitempty"`
+	} `json:"spec"`
+}
+
+type k0sctlK0s struct {
+	Version string `json:"version"`
+}
+
+// k0sctlRole returns the k0s role of a machine, from its role label or its
+// template machine name.
+func k0sctlRole(machine *Machine) (string, error) {
+	if role, ok := machine.spec.Labels[K0sctlRoleLabel]; ok {
+		for _, r := range append(k0sctlRoles, k0sctlNoRole) {
+			if role == r {
+				return role, nil
+			}
+		}
+		return "", fmt.Errorf("%s: invalid %s label %q, should be one of %s", machine.Hostname(), K0sctlRoleLabel, role, strings.Join(append(k0sctlRoles, k0sctlNoRole), ", "))
+	}
+	name := strings.ToLower(machine.spec.Name)
+	switch {
+	case strings.Contains(name, "single"):
+		return "single", nil
+	case strings.Contains(name, "controller") && strings.Contains(name, "worker"):
+		return "controller+worker", nil
+	case strings.Contains(name, "controller"), strings.Contains(name, "control-plane"), strings.Contains(name, "master"):
+		return "controller", nil
+	case strings.Contains(name, "worker"):
+		return "worker", nil
+	}
+	return "", fmt.Errorf("%s: cannot guess the k0s role from the machine name, set the %s label", machine.Hostname(), K0sctlRoleLabel)
+}
+
+// K0sctl writes a k0sctl cluster configuration of the running machines. The
+// SSH settings are the ones used by the ssh command.
+func (c *Cluster) K0sctl(w io.Writer, opts K0sctlOptions) error {
+	identity, err := c.privateKeyPath()
+	if err != nil {
+		return err
+	}
+
+	var bastion *k0sctlSSH
+	if opts.Bastion != "" {
+		machine, err := c.machineFromHostname(opts.Bastion)
+		if err != nil {
+			return err
+		}
+		if !machine.IsCreated() || !machine.IsStarted() {
+			return fmt.Errorf("%s: bastion machine is not running", opts.Bastion)
+		}
+		address, port, err := sshEndpoint(machine)
+		if err != nil {
+			return err
+		}
+		bastion = &k0sctlSSH{Address: address, Port: port, User: sshConfigUser, KeyPath: identity}
+	}
+
+	var machines []*Machine
+	err = c.forEachMachine(func(machine *Machine, _ int) error {
+		if machine.IsCreated() && machine.IsStarted() {
+			machines = append(machines, machine)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(machines) == 0 {
+		return fmt.Errorf("no running machines in cluster %s", c.spec.Cluster.Name)
+	}
+
+	if opts.Name == "" {
+		opts.Name = c.spec.Cluster.Name
+	}
+	var hosts []k0sctlHost
+	for _, machine := range machines {
+		role, err := k0sctlRole(machine)
+		if err != nil {
+			return err
+		}
+		if role == k0sctlNoRole {
+			continue
+		}
+		host := k0sctlHost{Role: role, Hostname: machine.Hostname()}
+		if bastion != nil || opts.PrivateAddresses {
+			if err := machine.loadAddresses(); err != nil {
+				return err
+			}
+			host.PrivateAddress = machine.ip
+		}
+		if bastion != nil {
+			host.SSH = k0sctlSSH{Address: machine.ip, Port: 22, User: sshConfigUser, KeyPath: identity, Bastion: bastion}
+		} else {
+			address, port, err := sshEndpoint(machine)
+			if err != nil {
+				return err
+			}
+			host.SSH = k0sctlSSH{Address: address, Port: port, User: sshConfigUser, KeyPath: identity}
+		}
+		hosts = append(hosts, host)
+	}
+	return writeK0sctlConfig(w, opts, hosts)
+}
+
+// writeK0sctlConfig writes a k0sctl cluster configuration with the given
+// hosts.
+func writeK0sctlConfig(w io.Writer, opts K0sctlOptions, hosts []k0sctlHost) error {
+	var conf k0sctlConfig
+	conf.APIVersion = "k0sctl.k0sproject.io/v1beta1"
+	conf.Kind = "Cluster"
+	conf.Metadata.Name = opts.Name
+	conf.Spec.Hosts = hosts
+	if opts.K0sVersion != "" {
+		conf.Spec.K0s = &k0sctlK0s{Version: opts.K0sVersion}
+	}
+	data, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestK0sctlRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		role   string
+		err    string
+	}{
+		{name: "controller%d", role: "controller"},
+		{name: "worker%d", role: "worker"},
+		{name: "controller-worker%d", role: "controller+worker"},
+		{name: "node%d", labels: map[string]string{"role": "single"}, role: "single"},
+		{name: "controller%d", labels: map[string]string{"role": "worker"}, role: "worker"},
+		{name: "lb%d", labels: map[string]string{"role": "none"}, role: "none"},
+		{name: "node%d", err: "cannot guess the k0s role"},
+		{name: "node%d", labels: map[string]string{"role": "leader"}, err: `invalid role label "leader"`},
+	}
+	for _, test := range tests {
+		machine := &Machine{spec: &config.Machine{Name: test.name, Labels: test.labels}, hostname: "node0"}
+		role, err := k0sctlRole(machine)
+		if test.err != "" {
+			assert.ErrorContains(t, err, test.err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.role, role, test.name)
+	}
+}
+
+func TestWriteK0sctlConfig(t *testing.T) {
+	bastion := &k0sctlSSH{Address: "localhost", Port: 2222, User: "root", KeyPath: "/tmp/cluster-key"}
+	hosts := []k0sctlHost{{
+		Role:           "controller",
+		Hostname:       "controller0",
+		PrivateAddress: "172.17.0.2",
+		SSH:            k0sctlSSH{Address: "172.17.0.2", Port: 22, User: "root", KeyPath: "/tmp/cluster-key", Bastion: bastion},
+	}}
+	var buf bytes.Buffer
+	require.NoError(t, writeK0sctlConfig(&buf, K0sctlOptions{Name: "cluster", K0sVersion: "v1.30.0+k0s.0"}, hosts))
+	assert.Equal(t, `apiVersion: k0sctl.k0sproject.io/v1beta1
+kind: Cluster
+metadata:
+  name: cluster
+spec:
+  hosts:
+  - hostname: controller0
+    privateAddress: 172.17.0.2
+    role: controller
+    ssh:
+      address: 172.17.0.2
+      bastion:
+        address: localhost
+        keyPath: /tmp/cluster-key
+        port: 2222
+        user: root
+      keyPath: /tmp/cluster-key
+      port: 22
+      user: root
+  k0s:
+    version: v1.30.0+k0s.0
+`, buf.String())
+}
@@ -5,9 +5,14 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // KeyStore is a store for public keys.
@@ -22,6 +27,16 @@ func NewKeyStore(basePath string) *KeyStore {
 	}
 }
 
+// DefaultKeyStorePath returns the default location of the key store, in the
+// user configuration directory, eg. ~/.config/bootloose/keys on Linux.
+func DefaultKeyStorePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bootloose", "keys"), nil
+}
+
 // Init initializes the key store, creating the store directory if needed.
 func (s *KeyStore) Init() error {
 	return os.MkdirAll(s.basePath, 0760)
@@ -42,22 +57,102 @@ func (s *KeyStore) keyExists(name string) bool {
 	return fileExists(s.keyPath(name))
 }
 
-// Store adds the key to the store.
+// validKeyName checks a key name can be used as a file name of the store.
+func validKeyName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("key store: invalid key name '%s'", name)
+	}
+	return nil
+}
+
+// Exists returns true when the store has a key with this name.
+func (s *KeyStore) Exists(name string) bool {
+	return validKeyName(name) == nil && s.keyExists(name)
+}
+
+// Store adds the key to the store, replacing the key of the same name if
+// any, eg. when rotating it.
 func (s *KeyStore) Store(name, key string) error {
-	if s.keyExists(name) {
-		return fmt.Errorf("key store: store: key '%s' already exists", name)
+	if err := validKeyName(name); err != nil {
+		return err
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key)); err != nil {
+		return fmt.Errorf("key store: store: key '%s' is not a valid public key: %w", name, err)
 	}
 
-	if err := os.WriteFile(s.keyPath(name), []byte(key), 0644); err != nil {
+	// Write a temporary file and rename it so the key is replaced atomically.
+	tmp, err := os.CreateTemp(s.basePath, "."+name+".*")
+	if err != nil {
+		return fmt.Errorf("key store: write: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strings.TrimSpace(key) + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("key store: write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("key store: write: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("key store: write: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.keyPath(name)); err != nil {
 		return fmt.Errorf("key store: write: %w", err)
 	}
 
 	return nil
 }
 
+// List returns the names of the keys in the store.
+func (s *KeyStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.basePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key store: list: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// KeyInfo describes a key of the store.
+type KeyInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+	Comment     string `json:"comment,omitempty"`
+	Key         string `json:"key"`
+}
+
+// Info returns the description of a key.
+func (s *KeyStore) Info(name string) (*KeyInfo, error) {
+	data, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	key, comment, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("key store: info: key '%s' is not a valid public key: %w", name, err)
+	}
+	return &KeyInfo{
+		Name:        name,
+		Type:        key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+		Comment:     comment,
+		Key:         strings.TrimSpace(string(data)),
+	}, nil
+}
+
 // Get retrieves a key from the store.
 func (s *KeyStore) Get(name string) ([]byte, error) {
-	if !s.keyExists(name) {
+	if !s.Exists(name) {
 		return nil, fmt.Errorf("key store: get: unknown key '%s'", name)
 	}
 	return os.ReadFile(s.keyPath(name))
@@ -65,7 +160,7 @@ func (s *KeyStore) Get(name string) ([]byte, error) {
 
 // Remove removes a key from the store.
 func (s *KeyStore) Remove(name string) error {
-	if !s.keyExists(name) {
+	if !s.Exists(name) {
 		return fmt.Errorf("key store: remove: unknown key '%s'", name)
 	}
 	if err := os.Remove(s.keyPath(name)); err != nil {
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestKeyStore(t *testing.T) {
+	store := NewKeyStore(t.TempDir())
+	require.NoError(t, store.Init())
+	_, alice, err := generateEd25519Key()
+	require.NoError(t, err)
+	_, rotated, err := generateEd25519Key()
+	require.NoError(t, err)
+
+	require.NoError(t, store.Store("alice", string(alice)))
+	require.NoError(t, store.Store("alice", string(rotated)))
+	data, err := store.Get("alice")
+	require.NoError(t, err)
+	assert.Equal(t, string(rotated), string(data))
+
+	require.NoError(t, store.Store("bob", string(alice)))
+	names, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, names)
+
+	info, err := store.Info("bob")
+	require.NoError(t, err)
+	assert.Equal(t, "ssh-ed25519", info.Type)
+	assert.True(t, strings.HasPrefix(info.Fingerprint, "SHA256:"))
+	assert.Equal(t, strings.TrimSpace(string(alice)), info.Key)
+
+	assert.Error(t, store.Store("carol", "not a key"))
+	assert.Error(t, store.Store("../carol", string(alice)))
+	assert.False(t, store.Exists("carol"))
+
+	require.NoError(t, store.Remove("bob"))
+	assert.Error(t, store.Remove("bob"))
+	names, err = store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, names)
+
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{Name: "cluster"}}, keyStore: store}
+	require.NoError(t, store.Store("bob", string(alice)))
+	machine := c.machine(&config.Machine{Name: "node%d", PublicKey: "alice", PublicKeys: []string{"bob"}}, 0)
+	keys, err := c.publicKey(machine)
+	require.NoError(t, err)
+	assert.Equal(t, string(rotated)+string(alice), string(keys))
+}
+
+func TestUsesKeyStore(t *testing.T) {
+	c := &Cluster{spec: config.Config{Machines: []config.MachineReplicas{
+		{Count: 1, Spec: &config.Machine{Name: "node%d"}},
+	}}}
+	assert.False(t, c.UsesKeyStore())
+
+	c.spec.Machines = append(c.spec.Machines, config.MachineReplicas{Count: 1, Spec: &config.Machine{Name: "admin%d", PublicKeys: []string{"alice"}}})
+	assert.True(t, c.UsesKeyStore())
+
+	machine := c.machine(c.spec.Machines[1].Spec, 0)
+	_, err := c.publicKey(machine)
+	assert.ErrorContains(t, err, "no key store to get the machine public keys from")
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Key types of the generated cluster key pair.
+const (
+	KeyTypeEd25519   = "ed25519"
+	KeyTypeRSA3072   = "rsa-3072"
+	KeyTypeRSA4096   = "rsa-4096"
+	KeyTypeECDSAP256 = "ecdsa-p256"
+	KeyTypeECDSAP384 = "ecdsa-p384"
+)
+
+// generateKey returns a new key pair of the given type, Ed25519 by default,
+// the private key PEM encoded and the public key in the authorized_keys
+// format.
+func generateKey(keyType string) ([]byte, []byte, error) {
+	var priv crypto.Signer
+	var err error
+	switch keyType {
+	case "", KeyTypeEd25519:
+		return generateEd25519Key()
+	case KeyTypeRSA3072:
+		priv, err = rsa.GenerateKey(rand.Reader, 3072)
+	case KeyTypeRSA4096:
+		priv, err = rsa.GenerateKey(rand.Reader, 4096)
+	case KeyTypeECDSAP256:
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeECDSAP384:
+		priv, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("unknown key type %q", keyType)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate new %s key: %w", keyType, err)
+	}
+	sshPub, err := ssh.NewPublicKey(priv.Public())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert %s public key into SSH public key: %w", keyType, err)
+	}
+	privPEM, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert %s private key into PEM block: %w", keyType, err)
+	}
+	return pem.EncodeToMemory(privPEM), ssh.MarshalAuthorizedKey(sshPub), nil
+}
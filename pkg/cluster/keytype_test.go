@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		keyType string
+		algo    string
+	}{
+		{"", ssh.KeyAlgoED25519},
+		{KeyTypeEd25519, ssh.KeyAlgoED25519},
+		{KeyTypeRSA3072, ssh.KeyAlgoRSA},
+		{KeyTypeECDSAP256, ssh.KeyAlgoECDSA256},
+		{KeyTypeECDSAP384, ssh.KeyAlgoECDSA384},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algo, func(t *testing.T) {
+			priv, pub, err := generateKey(tt.keyType)
+			require.NoError(t, err)
+			signer, err := ssh.ParsePrivateKey(priv)
+			require.NoError(t, err)
+			key, _, _, _, err := ssh.ParseAuthorizedKey(pub)
+			require.NoError(t, err)
+			assert.Equal(t, tt.algo, key.Type())
+			assert.Equal(t, key.Marshal(), signer.PublicKey().Marshal())
+		})
+	}
+
+	_, _, err := generateKey("dsa")
+	assert.ErrorContains(t, err, `unknown key type "dsa"`)
+}
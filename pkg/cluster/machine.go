@@ -22,8 +22,15 @@ type Machine struct {
 	name string
 	// container hostname.
 	hostname string
-	// container ip.
+	// index of the machine in its template, as used in the hostname.
+	index int
+	// container ip, the IPv4 address unless the machine only has IPv6.
 	ip string
+	// container global IPv6 address.
+	ipv6 string
+	// host ports picked for each port mapping when creating the machine, 0
+	// letting docker allocate one.
+	hostPorts []uint16
 
 	runtimeNetworks []*RuntimeNetwork
 	// Fields that are cached from the docker daemon.
@@ -113,6 +120,33 @@ func (m *Machine) networks() ([]*RuntimeNetwork, error) {
 	return m.runtimeNetworks, nil
 }
 
+// loadAddresses sets the machine addresses from its runtime networks, unless
+// already known.
+func (m *Machine) loadAddresses() error {
+	networks, err := m.networks()
+	if err != nil {
+		return err
+	}
+	// Since Docker 29.x the IPAddress field is deprecated and will not be set at NetworkSettings level
+	// Instead we need to check the Networks map and pick first address we find
+	for _, netw := range networks {
+		if m.ip == "" {
+			m.ip = netw.IP
+		}
+		if m.ipv6 == "" {
+			m.ipv6 = netw.IPv6
+		}
+	}
+	// IPv6 only machines
+	if m.ip == "" {
+		m.ip = m.ipv6
+	}
+	if m.ip == "" {
+		return fmt.Errorf("unable to determine IP address for machine %s", m.name)
+	}
+	return nil
+}
+
 func (m *Machine) dockerStatus(s *MachineStatus) error {
 	var ports []port
 	if m.IsCreated() {
@@ -149,6 +183,7 @@ func (m *Machine) Status() *MachineStatus {
 	s.Spec = m.spec
 	s.Hostname = m.Hostname()
 	s.IP = m.ip
+	s.IPv6 = m.ipv6
 	state := NotCreated
 
 	if m.IsCreated() {
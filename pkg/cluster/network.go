@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/k0sproject/bootloose/pkg/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+const clusterLabel = "io.k0sproject.bootloose.cluster"
+
+func (c *Cluster) createNetworkArgs(network config.Network) []string {
+	args := []string{
+		"--label", "io.k0sproject.bootloose.owner=bootloose",
+		"--label", clusterLabel + "=" + c.spec.Cluster.Name,
+	}
+	if network.Driver != "" {
+		args = append(args, "--driver", network.Driver)
+	}
+	if network.Subnet != "" {
+		args = append(args, "--subnet", network.Subnet)
+	}
+	if network.Gateway != "" {
+		args = append(args, "--gateway", network.Gateway)
+	}
+	if network.IPv6 {
+		args = append(args, "--ipv6")
+	}
+	if network.IPv6Subnet != "" {
+		args = append(args, "--subnet", network.IPv6Subnet)
+	}
+	if network.IPv6Gateway != "" {
+		args = append(args, "--gateway", network.IPv6Gateway)
+	}
+	if network.Internal {
+		args = append(args, "--internal")
+	}
+	return args
+}
+
+// networkOwner returns the name of the cluster owning network, or "" if the
+// network wasn't created by bootloose.
+func networkOwner(network string) (string, error) {
+	lines, err := docker.InspectNetwork(network, f(`{{index .Labels "%s"}}`, clusterLabel))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.Join(lines, "")), nil
+}
+
+// createNetworks creates the networks declared in the cluster configuration.
+// Existing networks are reused as is.
+func (c *Cluster) createNetworks() error {
+	for _, network := range c.spec.Cluster.Networks {
+		exists, err := docker.NetworkExists(network.Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			log.Infof("Network %s already exists...", network.Name)
+			continue
+		}
+		log.Infof("Creating network: %s ...", network.Name)
+		if err := docker.CreateNetwork(network.Name, c.createNetworkArgs(network)); err != nil {
+			return fmt.Errorf("failed to create network %s: %w", network.Name, err)
+		}
+	}
+	return nil
+}
+
+// deleteNetworks removes the networks declared in the cluster configuration
+// and created by this cluster.
+func (c *Cluster) deleteNetworks() error {
+	for _, network := range c.spec.Cluster.Networks {
+		exists, err := docker.NetworkExists(network.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		owner, err := networkOwner(network.Name)
+		if err != nil {
+			return err
+		}
+		if owner != c.spec.Cluster.Name {
+			log.Infof("Network %s wasn't created by this cluster, keeping it...", network.Name)
+			continue
+		}
+		log.Infof("Deleting network: %s ...", network.Name)
+		if err := docker.RemoveNetwork(network.Name); err != nil {
+			return fmt.Errorf("failed to delete network %s: %w", network.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkAddresses checks that the static addresses of the machines fit the
+// subnets of the networks that aren't declared in the cluster configuration.
+func (c *Cluster) checkAddresses() error {
+	managed := map[string]bool{}
+	for _, network := range c.spec.Cluster.Networks {
+		managed[network.Name] = true
+	}
+	subnets := map[string][]*net.IPNet{}
+	for _, template := range c.spec.Machines {
+		for _, address := range template.Spec.Addresses {
+			if managed[address.Network] {
+				continue
+			}
+			if _, ok := subnets[address.Network]; !ok {
+				lines, err := docker.InspectNetwork(address.Network, "{{range .IPAM.Config}}{{.Subnet}} {{end}}")
+				if err != nil {
+					return err
+				}
+				list := []*net.IPNet{}
+				for _, cidr := range strings.Fields(strings.Join(lines, " ")) {
+					if _, subnet, err := net.ParseCIDR(cidr); err == nil {
+						list = append(list, subnet)
+					}
+				}
+				if len(list) == 0 {
+					return fmt.Errorf("network %s has no user configured subnet, static addresses cannot be used", address.Network)
+				}
+				subnets[address.Network] = list
+			}
+			for i := 0; i < template.Count; i++ {
+				ip, err := address.IPv4(i)
+				if err != nil {
+					return err
+				}
+				if !slices.ContainsFunc(subnets[address.Network], func(subnet *net.IPNet) bool { return config.SubnetContains(subnet, ip) }) {
+					return fmt.Errorf("%s: address %s is not in a subnet of network %s", f(template.Spec.Name, i), ip, address.Network)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/k0sproject/bootloose/pkg/exec"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// portForwardDialTimeout is how long to wait for the machine address to
+	// answer before falling back to docker exec.
+	portForwardDialTimeout = 2 * time.Second
+	// udpFlowTimeout is how long a UDP client is remembered without replies.
+	udpFlowTimeout = time.Minute
+	udpBufferSize  = 64 * 1024
+)
+
+// PortForward describes forwarding a host port to a machine port.
+type PortForward struct {
+	// HostPort is the host port to listen on, 0 to pick a random port.
+	HostPort uint16
+	// ContainerPort is the machine port to forward to.
+	ContainerPort uint16
+	// Protocol is either "tcp" or "udp".
+	Protocol string
+}
+
+func (p PortForward) String() string {
+	return f("%d:%d/%s", p.HostPort, p.ContainerPort, p.Protocol)
+}
+
+// ParsePortForward parses a [hostPort:]containerPort[/protocol] port forward.
+// Without host port, the host port is the same as the container port, and an
+// empty host port, eg. ":443", picks a random port.
+func ParsePortForward(spec string) (PortForward, error) {
+	forward := PortForward{Protocol: "tcp"}
+	ports, protocol, ok := strings.Cut(spec, "/")
+	if ok {
+		if protocol != "tcp" && protocol != "udp" {
+			return forward, fmt.Errorf("%s: protocol should be tcp or udp", spec)
+		}
+		forward.Protocol = protocol
+	}
+	hostPort, containerPort, ok := strings.Cut(ports, ":")
+	if !ok {
+		containerPort = hostPort
+	}
+	port, err := strconv.ParseUint(containerPort, 10, 16)
+	if err != nil || port == 0 {
+		return forward, fmt.Errorf("%s: invalid container port %q", spec, containerPort)
+	}
+	forward.ContainerPort = uint16(port)
+	if !ok {
+		forward.HostPort = forward.ContainerPort
+		return forward, nil
+	}
+	if hostPort != "" {
+		port, err = strconv.ParseUint(hostPort, 10, 16)
+		if err != nil {
+			return forward, fmt.Errorf("%s: invalid host port %q", spec, hostPort)
+		}
+		forward.HostPort = uint16(port)
+	}
+	return forward, nil
+}
+
+// portForwarder forwards the host ports to one machine.
+type portForwarder struct {
+	machine *Machine
+	// exec is true when the machine address isn't routable from the host and
+	// connections go through docker exec.
+	exec bool
+}
+
+// PortForward forwards host ports bound on address to the machine until ctx is
+// done. Connections go to the machine address, or through docker exec with
+// socat or nc when this address can't be reached from the host.
+func (c *Cluster) PortForward(ctx context.Context, hostname, address string, forwards []PortForward) error {
+	machine, err := c.machineFromHostname(hostname)
+	if err != nil {
+		return err
+	}
+	if !machine.IsCreated() || !machine.IsStarted() {
+		return fmt.Errorf("%s: machine is not started", hostname)
+	}
+	if err := machine.loadAddresses(); err != nil {
+		return err
+	}
+	forwarder := &portForwarder{machine: machine}
+	probe := uint16(22)
+	for _, forward := range forwards {
+		if forward.Protocol == "tcp" {
+			probe = forward.ContainerPort
+			break
+		}
+	}
+	if !routable(machine.ip, probe) {
+		log.Infof("%s is not reachable from the host, forwarding through docker exec", machine.ip)
+		forwarder.exec = true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		ferr error
+	)
+	for _, forward := range forwards {
+		listen := net.JoinHostPort(address, strconv.Itoa(int(forward.HostPort)))
+		var serve func() error
+		if forward.Protocol == "udp" {
+			conn, err := net.ListenPacket("udp", listen)
+			if err != nil {
+				return err
+			}
+			log.Infof("Forwarding %s/udp -> %s:%d/udp", conn.LocalAddr(), hostname, forward.ContainerPort)
+			go func() { <-ctx.Done(); _ = conn.Close() }()
+			serve = func() error { return forwarder.serveUDP(ctx, conn, forward.ContainerPort) }
+		} else {
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return err
+			}
+			log.Infof("Forwarding %s/tcp -> %s:%d/tcp", listener.Addr(), hostname, forward.ContainerPort)
+			go func() { <-ctx.Done(); _ = listener.Close() }()
+			serve = func() error { return forwarder.serveTCP(ctx, listener, forward.ContainerPort) }
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := serve(); err != nil {
+				once.Do(func() { ferr = err })
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	return ferr
+}
+
+// routable returns true when the machine address answers from the host, a
+// refused connection meaning the machine was reached.
+func routable(ip string, port uint16) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(int(port))), portForwardDialTimeout)
+	if err == nil {
+		_ = conn.Close()
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func (p *portForwarder) serveTCP(ctx context.Context, listener net.Listener, port uint16) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := p.forwardTCP(conn, port); err != nil {
+				log.Warnf("%s: forwarding %s: %v", p.machine.Hostname(), conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (p *portForwarder) forwardTCP(conn net.Conn, port uint16) error {
+	if p.exec {
+		return p.execForward(conn, "TCP", port)
+	}
+	upstream, err := net.DialTimeout("tcp", net.JoinHostPort(p.machine.ip, strconv.Itoa(int(port))), portForwardDialTimeout)
+	if err != nil {
+		return err
+	}
+	defer upstream.Close()
+	relay(conn, upstream)
+	return nil
+}
+
+// relay copies data both ways between two connections until both directions
+// are closed.
+func relay(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		if tcp, ok := dst.(*net.TCPConn); ok {
+			_ = tcp.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go pipe(a, b)
+	go pipe(b, a)
+	<-done
+	<-done
+}
+
+// execForwardScript is a shell script piping its standard input and output
+// to a local port through socat, or nc when socat isn't installed.
+func execForwardScript(protocol string, port uint16) string {
+	ncFlags := ""
+	if protocol == "UDP" {
+		ncFlags = "-u "
+	}
+	return f(`if command -v socat >/dev/null 2>&1; then exec socat - %s:localhost:%d; `+
+		`elif command -v nc >/dev/null 2>&1; then exec nc %slocalhost %d; `+
+		`else echo "socat or nc is required to forward ports" >&2; exit 127; fi`,
+		protocol, port, ncFlags, port)
+}
+
+// execForward pipes conn to the machine port through socat, or nc when socat
+// isn't installed, run by docker exec.
+func (p *portForwarder) execForward(conn io.ReadWriter, protocol string, port uint16) error {
+	script := execForwardScript(protocol, port)
+	cmd := exec.Command("docker", "exec", "-i", p.machine.ContainerName(), "/bin/sh", "-c", script)
+	var stderr strings.Builder
+	cmd.SetStdin(conn)
+	cmd.SetStdout(conn)
+	cmd.SetStderr(&stderr)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (p *portForwarder) serveUDP(ctx context.Context, conn net.PacketConn, port uint16) error {
+	// flows to the machine port, by client address
+	var mu sync.Mutex
+	flows := map[string]io.ReadWriteCloser{}
+	buf := make([]byte, udpBufferSize)
+	for {
+		n, client, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		upstream, ok := flows[client.String()]
+		if !ok {
+			upstream, err = p.dialUDP(port)
+			if err != nil {
+				mu.Unlock()
+				log.Warnf("%s: forwarding %s: %v", p.machine.Hostname(), client, err)
+				continue
+			}
+			flows[client.String()] = upstream
+			go func() {
+				reply := make([]byte, udpBufferSize)
+				for {
+					if c, ok := upstream.(net.Conn); ok {
+						_ = c.SetReadDeadline(time.Now().Add(udpFlowTimeout))
+					}
+					n, err := upstream.Read(reply)
+					if err != nil {
+						break
+					}
+					if _, err := conn.WriteTo(reply[:n], client); err != nil {
+						break
+					}
+				}
+				mu.Lock()
+				delete(flows, client.String())
+				mu.Unlock()
+				_ = upstream.Close()
+			}()
+		}
+		mu.Unlock()
+		if _, err := upstream.Write(buf[:n]); err != nil {
+			log.Warnf("%s: forwarding %s: %v", p.machine.Hostname(), client, err)
+		}
+	}
+}
+
+// dialUDP opens a UDP flow to the machine port, directly or through docker
+// exec.
+func (p *portForwarder) dialUDP(port uint16) (io.ReadWriteCloser, error) {
+	if !p.exec {
+		return net.Dial("udp", net.JoinHostPort(p.machine.ip, strconv.Itoa(int(port))))
+	}
+	local, remote := net.Pipe()
+	go func() {
+		defer remote.Close()
+		if err := p.execForward(remote, "UDP", port); err != nil {
+			log.Warnf("%s: forwarding udp port %d: %v", p.machine.Hostname(), port, err)
+		}
+	}()
+	return local, nil
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePortForward(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected PortForward
+		err      string
+	}{
+		{spec: "443", expected: PortForward{HostPort: 443, ContainerPort: 443, Protocol: "tcp"}},
+		{spec: "8443:443", expected: PortForward{HostPort: 8443, ContainerPort: 443, Protocol: "tcp"}},
+		{spec: ":443", expected: PortForward{ContainerPort: 443, Protocol: "tcp"}},
+		{spec: "5353:53/udp", expected: PortForward{HostPort: 5353, ContainerPort: 53, Protocol: "udp"}},
+		{spec: "8443:0", err: `invalid container port "0"`},
+		{spec: "x:443", err: `invalid host port "x"`},
+		{spec: "53/sctp", err: "protocol should be tcp or udp"},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			forward, err := ParsePortForward(test.spec)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, forward)
+		})
+	}
+}
+
+func TestPortForwarder(t *testing.T) {
+	forwarder := &portForwarder{machine: &Machine{hostname: "node0", ip: "127.0.0.1"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("TCP", func(t *testing.T) {
+		echo, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer echo.Close()
+		go func() {
+			for {
+				conn, err := echo.Accept()
+				if err != nil {
+					return
+				}
+				go func() { _, _ = io.Copy(conn, conn); conn.Close() }()
+			}
+		}()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() { _ = forwarder.serveTCP(ctx, listener, uint16(echo.Addr().(*net.TCPAddr).Port)) }()
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf))
+	})
+
+	t.Run("UDP", func(t *testing.T) {
+		echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer echo.Close()
+		go func() {
+			buf := make([]byte, 1024)
+			for {
+				n, addr, err := echo.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				_, _ = echo.WriteTo(buf[:n], addr)
+			}
+		}()
+
+		listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() { _ = forwarder.serveUDP(ctx, listener, uint16(echo.LocalAddr().(*net.UDPAddr).Port)) }()
+
+		conn, err := net.Dial("udp", listener.LocalAddr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf[:n]))
+	})
+}
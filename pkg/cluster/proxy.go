@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SOCKS5 protocol values, see RFC 1928.
+const (
+	socksVersion = 0x05
+
+	socksNoAuth       = 0x00
+	socksNoAcceptable = 0xff
+
+	socksConnect = 0x01
+
+	socksIPv4   = 0x01
+	socksDomain = 0x03
+	socksIPv6   = 0x04
+
+	socksSucceeded           = 0x00
+	socksGeneralFailure      = 0x01
+	socksHostUnreachable     = 0x04
+	socksConnectionRefused   = 0x05
+	socksCommandNotSupported = 0x07
+	socksAddressNotSupported = 0x08
+)
+
+// Proxy runs a SOCKS5 proxy listening on listen until ctx is done. Machine
+// hostnames, optionally followed by the cluster domain, and container names
+// are resolved to the machine addresses. Other destinations are dialed from
+// the host.
+func (c *Cluster) Proxy(ctx context.Context, listen string) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	log.Infof("SOCKS5 proxy listening on %s", listener.Addr())
+	go func() { <-ctx.Done(); _ = listener.Close() }()
+	return c.serveProxy(ctx, listener)
+}
+
+func (c *Cluster) serveProxy(ctx context.Context, listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := c.proxyConn(conn); err != nil {
+				log.Debugf("proxy: %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// proxyConn serves a SOCKS5 client, only supporting the CONNECT command
+// without authentication.
+func (c *Cluster) proxyConn(conn net.Conn) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[0] != socksVersion {
+		return fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+	method := byte(socksNoAcceptable)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return err
+	}
+	if method == socksNoAcceptable {
+		return errors.New("client doesn't support connecting without authentication")
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return err
+	}
+	if request[1] != socksConnect {
+		_ = socksReply(conn, socksCommandNotSupported)
+		return fmt.Errorf("unsupported SOCKS command %d", request[1])
+	}
+	var host string
+	switch request[3] {
+	case socksIPv4, socksIPv6:
+		ip := make([]byte, net.IPv4len)
+		if request[3] == socksIPv6 {
+			ip = make([]byte, net.IPv6len)
+		}
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return err
+		}
+		host = net.IP(ip).String()
+	case socksDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return err
+		}
+		host = string(domain)
+	default:
+		_ = socksReply(conn, socksAddressNotSupported)
+		return fmt.Errorf("unsupported SOCKS address type %d", request[3])
+	}
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBytes); err != nil {
+		return err
+	}
+	port := binary.BigEndian.Uint16(portBytes)
+
+	upstream, err := c.proxyDial(host, port)
+	if err != nil {
+		var opErr *net.OpError
+		code := byte(socksGeneralFailure)
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			code = socksConnectionRefused
+		} else if errors.As(err, &opErr) {
+			code = socksHostUnreachable
+		}
+		_ = socksReply(conn, code)
+		return fmt.Errorf("%s: %w", net.JoinHostPort(host, strconv.Itoa(int(port))), err)
+	}
+	defer upstream.Close()
+	if err := socksReply(conn, socksSucceeded); err != nil {
+		return err
+	}
+	relay(conn, upstream)
+	return nil
+}
+
+// socksReply sends a reply with the given code. The bound address isn't
+// meaningful to clients of a CONNECT proxy and is left unspecified.
+func socksReply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socksVersion, code, 0x00, socksIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// proxyMachine returns the machine a proxy destination refers to, if any.
+func (c *Cluster) proxyMachine(host string) *Machine {
+	for _, machine := range c.gatherMachinesByCluster() {
+		switch host {
+		case machine.Hostname(), machine.ContainerName():
+			return machine
+		}
+		if c.spec.Cluster.Domain != "" && host == machine.Hostname()+"."+c.spec.Cluster.Domain {
+			return machine
+		}
+	}
+	return nil
+}
+
+// proxyDial connects to a proxy destination. Machines are dialed at their
+// address, or through docker exec when it can't be reached from the host.
+func (c *Cluster) proxyDial(host string, port uint16) (net.Conn, error) {
+	machine := c.proxyMachine(host)
+	if machine == nil {
+		return net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))), portForwardDialTimeout)
+	}
+	if !machine.IsCreated() || !machine.IsStarted() {
+		return nil, fmt.Errorf("%s: machine is not started", machine.Hostname())
+	}
+	if err := machine.loadAddresses(); err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(machine.ip, strconv.Itoa(int(port))), portForwardDialTimeout)
+	if err == nil || errors.Is(err, syscall.ECONNREFUSED) {
+		return conn, err
+	}
+	log.Debugf("proxy: %s is not reachable from the host, connecting through docker exec", machine.ip)
+	local, remote := net.Pipe()
+	forwarder := &portForwarder{machine: machine, exec: true}
+	go func() {
+		defer remote.Close()
+		if err := forwarder.execForward(remote, "TCP", port); err != nil {
+			log.Warnf("%s: proxying port %d: %v", machine.Hostname(), port, err)
+		}
+	}()
+	return local, nil
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+  domain: cluster.local
+machines:
+- count: 2
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "node1", cluster.proxyMachine("node1").Hostname())
+	assert.Equal(t, "node1", cluster.proxyMachine("node1.cluster.local").Hostname())
+	assert.Equal(t, "node0", cluster.proxyMachine("cluster-node0").Hostname())
+	assert.Nil(t, cluster.proxyMachine("node2"))
+
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _, _ = io.Copy(conn, conn); conn.Close() }()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() { _ = cluster.serveProxy(ctx, listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte{socksVersion, 1, socksNoAuth})
+	require.NoError(t, err)
+	reply := make([]byte, 2)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{socksVersion, socksNoAuth}, reply)
+
+	host := "localhost"
+	request := append([]byte{socksVersion, socksConnect, 0x00, socksDomain, byte(len(host))}, host...)
+	request = binary.BigEndian.AppendUint16(request, uint16(echo.Addr().(*net.TCPAddr).Port))
+	_, err = conn.Write(request)
+	require.NoError(t, err)
+	reply = make([]byte, 10)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, byte(socksSucceeded), reply[1])
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
@@ -0,0 +1,273 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// retiredKeysSuffix is appended to the private key path to name the file of
+// the public keys retired by rotations, removed from the machines that were
+// stopped during the rotation when they start.
+const retiredKeysSuffix = ".retired"
+
+// rotateVerifyTimeout is how long to try logging in with the new key.
+const rotateVerifyTimeout = 30 * time.Second
+
+// RotateKeyOptions customizes the rotation of the cluster key.
+type RotateKeyOptions struct {
+	// DryRun only prints the machines the rotation would update.
+	DryRun bool
+	// DeferStopped updates stopped machines when they next start, instead of
+	// failing.
+	DeferStopped bool
+	// Out receives the machines the rotation updates.
+	Out io.Writer
+}
+
+// keyBlob returns the base64 key of an authorized_keys line, which identifies
+// the key whatever its comment.
+func keyBlob(key []byte) string {
+	fields := strings.Fields(string(key))
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// authorizedKeysFiles returns the authorized_keys files the cluster key is
+// written to on a machine: root's and the ones of the users without their own
+// authorized keys.
+func (c *Cluster) authorizedKeysFiles(machine *Machine) []string {
+	files := []string{"/root/.ssh/authorized_keys"}
+	for _, user := range c.machineUsers(machine) {
+		if len(user.AuthorizedKeys) == 0 {
+			files = append(files, f(`"$(grep '^%s:' /etc/passwd | cut -d: -f6)/.ssh/authorized_keys"`, user.Name))
+		}
+	}
+	return files
+}
+
+// authorizeKeysScript adds a key, if not nil, to the authorized_keys files
+// and removes the retired keys from them. The files are rewritten in place to
+// keep their owner and mode.
+func authorizeKeysScript(files []string, key []byte, retired [][]byte) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "set -e\ntmp=$(mktemp)\nfor file in %s; do\n", strings.Join(files, " "))
+	fmt.Fprintf(&buf, "  [ -f \"$file\" ] || continue\n")
+	if key != nil {
+		fmt.Fprintf(&buf, "  grep -qF %s \"$file\" || printf '%%s\\n' %s >> \"$file\"\n",
+			shellQuote(keyBlob(key)), shellQuote(strings.TrimSpace(string(key))))
+	}
+	for _, old := range retired {
+		blob := shellQuote(keyBlob(old))
+		fmt.Fprintf(&buf, "  if grep -qF %s \"$file\"; then grep -vF %s \"$file\" > \"$tmp\" || true; cat \"$tmp\" > \"$file\"; fi\n", blob, blob)
+	}
+	fmt.Fprintf(&buf, "done\nrm -f \"$tmp\"\n")
+	return buf.String()
+}
+
+// authorizeKeys updates the authorized_keys files of a machine through
+// docker, which doesn't depend on the keys being rotated.
+func (c *Cluster) authorizeKeys(machine *Machine, key []byte, retired ...[]byte) error {
+	return containerRunShell(machine.ContainerName(), authorizeKeysScript(c.authorizedKeysFiles(machine), key, retired))
+}
+
+// writeKeyFile replaces a key file, writing a temporary file first.
+func writeKeyFile(path string, data []byte, mode os.FileMode) error {
+	if err := os.WriteFile(path+".tmp", data, mode); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// RotateKey replaces the cluster key pair with a new one of the cluster key
+// type. The new public key is authorized on the running machines, login with
+// the new key is verified, then the key files are replaced and the old key is
+// removed from the machines. Machines using key store keys aren't affected.
+// Stopped machines make the rotation fail, unless deferred to their next
+// start.
+func (c *Cluster) RotateKey(ctx context.Context, opts RotateKeyOptions) error {
+	if opts.Out == nil {
+		opts.Out = io.Discard
+	}
+	if c.spec.Cluster.AgentKey != "" {
+		return errors.New("the cluster key is an ssh agent key, rotating it is up to its owner")
+	}
+	if c.spec.Cluster.PrivateKey == "" {
+		return errors.New("the cluster has no private key to rotate")
+	}
+	path, err := expandHomedir(c.spec.Cluster.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to expand private key path: %w", err)
+	}
+	oldPub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return fmt.Errorf("failed to read the public key: %w", err)
+	}
+
+	var running, stopped []*Machine
+	err = c.forEachMachine(func(machine *Machine, _ int) error {
+		switch {
+		case len(keyNames(machine)) > 0 || !machine.IsCreated():
+		case machine.IsStarted():
+			running = append(running, machine)
+		default:
+			stopped = append(stopped, machine)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(stopped) > 0 && !opts.DeferStopped {
+		names := make([]string, len(stopped))
+		for i, machine := range stopped {
+			names[i] = machine.Hostname()
+		}
+		err := fmt.Errorf("stopped machines can't be updated: %s, start them or defer their update to their next start", strings.Join(names, ", "))
+		if !opts.DryRun {
+			return err
+		}
+		fmt.Fprintf(opts.Out, "The rotation would fail: %v\n", err)
+	}
+
+	verb := "Rotating"
+	if opts.DryRun {
+		verb = "Would rotate"
+	}
+	fmt.Fprintf(opts.Out, "%s the key %s on:\n", verb, path)
+	for _, machine := range running {
+		fmt.Fprintf(opts.Out, "  %s\n", machine.Hostname())
+	}
+	if opts.DeferStopped {
+		for _, machine := range stopped {
+			fmt.Fprintf(opts.Out, "  %s (stopped, updated when started)\n", machine.Hostname())
+		}
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	priv, pub, err := generateKey(c.spec.Cluster.KeyType)
+	if err != nil {
+		return err
+	}
+	for _, machine := range running {
+		log.Infof("Authorizing the new key on %s ...", machine.Hostname())
+		if err := c.authorizeKeys(machine, pub); err != nil {
+			return c.rollbackRotation(running, pub, fmt.Errorf("%s: %w", machine.Hostname(), err))
+		}
+	}
+
+	signer, err := ssh.ParsePrivateKey(priv)
+	if err != nil {
+		return c.rollbackRotation(running, pub, err)
+	}
+	for _, machine := range running {
+		log.Infof("Verifying login with the new key on %s ...", machine.Hostname())
+		verifyCtx, cancel := context.WithTimeout(ctx, rotateVerifyTimeout)
+		client, err := c.sshClient(verifyCtx, machine, sshConfigUser, []ssh.AuthMethod{ssh.PublicKeys(signer)})
+		cancel()
+		if err != nil {
+			return c.rollbackRotation(running, pub, err)
+		}
+		client.Close()
+	}
+
+	if len(stopped) > 0 {
+		retired, err := os.OpenFile(path+retiredKeysSuffix, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return c.rollbackRotation(running, pub, err)
+		}
+		_, err = retired.Write(oldPub)
+		if cerr := retired.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return c.rollbackRotation(running, pub, err)
+		}
+	}
+	if err := writeKeyFile(path, priv, 0o600); err != nil {
+		return c.rollbackRotation(running, pub, fmt.Errorf("failed to save private key: %w", err))
+	}
+	if err := writeKeyFile(path+".pub", pub, 0o644); err != nil {
+		return fmt.Errorf("failed to save public key, the private key is already replaced: %w", err)
+	}
+
+	for _, machine := range running {
+		log.Infof("Removing the old key from %s ...", machine.Hostname())
+		if err := c.authorizeKeys(machine, nil, oldPub); err != nil {
+			return fmt.Errorf("%s: failed to remove the old key: %w", machine.Hostname(), err)
+		}
+	}
+	return nil
+}
+
+// rollbackRotation removes the new key from the machines after a failed
+// rotation.
+func (c *Cluster) rollbackRotation(machines []*Machine, pub []byte, err error) error {
+	log.Warnf("Key rotation failed, removing the new key from the machines ...")
+	for _, machine := range machines {
+		if rerr := c.authorizeKeys(machine, nil, pub); rerr != nil {
+			log.Warnf("%s: failed to remove the new key: %v", machine.Hostname(), rerr)
+		}
+	}
+	return fmt.Errorf("key rotation failed, the old key is kept: %w", err)
+}
+
+// updateRetiredKeys authorizes the cluster key on the running machines and
+// removes the keys retired while they were stopped. The retired keys are
+// forgotten once no machine is left stopped.
+func (c *Cluster) updateRetiredKeys() error {
+	if c.spec.Cluster.PrivateKey == "" {
+		return nil
+	}
+	path, err := expandHomedir(c.spec.Cluster.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to expand private key path: %w", err)
+	}
+	data, err := os.ReadFile(path + retiredKeysSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return err
+	}
+	var retired [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if keyBlob(line) != "" && keyBlob(line) != keyBlob(pub) {
+			retired = append(retired, line)
+		}
+	}
+	stopped := false
+	err = c.forEachMachine(func(machine *Machine, _ int) error {
+		switch {
+		case len(keyNames(machine)) > 0 || !machine.IsCreated():
+			return nil
+		case !machine.IsStarted():
+			stopped = true
+			return nil
+		}
+		return c.authorizeKeys(machine, pub, retired...)
+	})
+	if err != nil || stopped {
+		return err
+	}
+	return os.Remove(path + retiredKeysSuffix)
+}
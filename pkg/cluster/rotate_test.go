@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestKeyBlob(t *testing.T) {
+	assert.Equal(t, "AAAA", keyBlob([]byte("ssh-ed25519 AAAA root@host\n")))
+	assert.Equal(t, "AAAA", keyBlob([]byte("ssh-ed25519 AAAA")))
+	assert.Equal(t, "", keyBlob([]byte("garbage")))
+}
+
+func TestAuthorizedKeysFiles(t *testing.T) {
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{Users: []config.User{
+		{Name: "k0s"},
+		{Name: "alice", AuthorizedKeys: []string{"ssh-ed25519 BBBB alice"}},
+	}}}}
+	machine := &Machine{spec: &config.Machine{}}
+	assert.Equal(t, []string{
+		"/root/.ssh/authorized_keys",
+		`"$(grep '^k0s:' /etc/passwd | cut -d: -f6)/.ssh/authorized_keys"`,
+	}, c.authorizedKeysFiles(machine))
+}
+
+func TestAuthorizeKeysScript(t *testing.T) {
+	script := authorizeKeysScript([]string{"/root/.ssh/authorized_keys"}, []byte("ssh-ed25519 NEW new-key\n"), [][]byte{[]byte("ssh-ed25519 OLD old-key")})
+	assert.Contains(t, script, "for file in /root/.ssh/authorized_keys; do\n")
+	assert.Contains(t, script, "  grep -qF 'NEW' \"$file\" || printf '%s\\n' 'ssh-ed25519 NEW new-key' >> \"$file\"\n")
+	assert.Contains(t, script, "  if grep -qF 'OLD' \"$file\"; then grep -vF 'OLD' \"$file\" > \"$tmp\" || true; cat \"$tmp\" > \"$file\"; fi\n")
+
+	script = authorizeKeysScript([]string{"/root/.ssh/authorized_keys"}, nil, [][]byte{[]byte("ssh-ed25519 OLD old-key")})
+	assert.NotContains(t, script, "printf")
+}
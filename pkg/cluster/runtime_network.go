@@ -6,28 +6,40 @@ package cluster
 
 import (
 	"net"
+	"sort"
 
 	"github.com/k0sproject/bootloose/pkg/api/docker/network"
 )
 
 const (
 	ipv4Length = 32
+	ipv6Length = 128
 )
 
-// NewRuntimeNetworks returns a slice of networks
+// NewRuntimeNetworks returns a slice of networks sorted by name
 func NewRuntimeNetworks(networks map[string]*network.EndpointSettings) []*RuntimeNetwork {
 	rnList := make([]*RuntimeNetwork, 0, len(networks))
 	for key, value := range networks {
-		mask := net.CIDRMask(value.IPPrefixLen, ipv4Length)
-		maskIP := net.IP(mask).String()
 		rnNetwork := &RuntimeNetwork{
-			Name:    key,
-			IP:      value.IPAddress,
-			Mask:    maskIP,
-			Gateway: value.Gateway,
+			Name:        key,
+			IP:          value.IPAddress,
+			Gateway:     value.Gateway,
+			IPv6:        value.GlobalIPv6Address,
+			IPv6Gateway: value.IPv6Gateway,
+		}
+		if value.IPAddress != "" {
+			rnNetwork.Mask = net.IP(net.CIDRMask(value.IPPrefixLen, ipv4Length)).String()
+		}
+		if value.GlobalIPv6Address != "" {
+			rnNetwork.IPv6Mask = net.IP(net.CIDRMask(value.GlobalIPv6PrefixLen, ipv6Length)).String()
+			rnNetwork.IPv6PrefixLen = value.GlobalIPv6PrefixLen
+		}
+		if value.IPAMConfig != nil && (value.IPAMConfig.IPv4Address != "" || value.IPAMConfig.IPv6Address != "") {
+			rnNetwork.Static = true
 		}
 		rnList = append(rnList, rnNetwork)
 	}
+	sort.Slice(rnList, func(i, j int) bool { return rnList[i].Name < rnList[j].Name })
 	return rnList
 }
 
@@ -41,4 +53,14 @@ type RuntimeNetwork struct {
 	Mask string `json:"mask,omitempty"`
 	// Gateway of the network
 	Gateway string `json:"gateway,omitempty"`
+	// IPv6 is the global IPv6 address of the container
+	IPv6 string `json:"ipv6,omitempty"`
+	// IPv6Mask is the mask of the IPv6 network
+	IPv6Mask string `json:"ipv6Mask,omitempty"`
+	// IPv6PrefixLen is the prefix length of the IPv6 network
+	IPv6PrefixLen int `json:"ipv6PrefixLen,omitempty"`
+	// IPv6Gateway is the IPv6 gateway of the network
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+	// Static is true when the IP was assigned from the machine addresses
+	Static bool `json:"static,omitempty"`
 }
@@ -25,4 +25,48 @@ func TestNewRuntimeNetworks(t *testing.T) {
 			&RuntimeNetwork{Name: "mynetwork", Gateway: "172.17.0.1", IP: "172.17.0.4", Mask: "255.255.0.0"}}
 		assert.Equal(t, expectedRuntimeNetworks, res)
 	})
+
+	t.Run("Static", func(t *testing.T) {
+		networks := map[string]*network.EndpointSettings{}
+		networks["mynetwork"] = &network.EndpointSettings{
+			IPAMConfig:  &network.EndpointIPAMConfig{IPv4Address: "172.30.0.10"},
+			Gateway:     "172.30.0.1",
+			IPAddress:   "172.30.0.10",
+			IPPrefixLen: 24,
+		}
+		res := NewRuntimeNetworks(networks)
+
+		expectedRuntimeNetworks := []*RuntimeNetwork{
+			{Name: "mynetwork", Gateway: "172.30.0.1", IP: "172.30.0.10", Mask: "255.255.255.0", Static: true}}
+		assert.Equal(t, expectedRuntimeNetworks, res)
+	})
+
+	t.Run("DualStack", func(t *testing.T) {
+		networks := map[string]*network.EndpointSettings{}
+		networks["v6only"] = &network.EndpointSettings{
+			IPv6Gateway:         "fd00:1::1",
+			GlobalIPv6Address:   "fd00:1::2",
+			GlobalIPv6PrefixLen: 64,
+		}
+		networks["dualstack"] = &network.EndpointSettings{
+			Gateway:             "172.30.0.1",
+			IPAddress:           "172.30.0.2",
+			IPPrefixLen:         16,
+			IPv6Gateway:         "fd00:2::1",
+			GlobalIPv6Address:   "fd00:2::2",
+			GlobalIPv6PrefixLen: 48,
+		}
+		res := NewRuntimeNetworks(networks)
+
+		expectedRuntimeNetworks := []*RuntimeNetwork{
+			{
+				Name: "dualstack", IP: "172.30.0.2", Mask: "255.255.0.0", Gateway: "172.30.0.1",
+				IPv6: "fd00:2::2", IPv6Mask: "ffff:ffff:ffff::", IPv6PrefixLen: 48, IPv6Gateway: "fd00:2::1",
+			},
+			{
+				Name: "v6only", IPv6: "fd00:1::2", IPv6Mask: "ffff:ffff:ffff:ffff::", IPv6PrefixLen: 64, IPv6Gateway: "fd00:1::1",
+			},
+		}
+		assert.Equal(t, expectedRuntimeNetworks, res)
+	})
 }
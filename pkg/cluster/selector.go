@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Label selector operators.
+const (
+	selectEquals = iota
+	selectNotEquals
+	selectExists
+	selectNotExists
+)
+
+// labelRequirement is a condition on a machine label.
+type labelRequirement struct {
+	key   string
+	op    int
+	value string
+}
+
+// Selector selects machines by label, eg. "role=worker,zone!=b". The
+// requirements are separated by commas and have to be all met. Besides
+// key=value and key!=value, "key" requires the label and "!key" its absence.
+type Selector []labelRequirement
+
+// ParseSelector parses a label selector.
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		var req labelRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			req.key, req.value, _ = strings.Cut(term, "!=")
+			req.op = selectNotEquals
+		case strings.Contains(term, "="):
+			req.key, req.value, _ = strings.Cut(term, "=")
+			req.value = strings.TrimPrefix(req.value, "=")
+			req.op = selectEquals
+		case strings.HasPrefix(term, "!"):
+			req.key = term[1:]
+			req.op = selectNotExists
+		default:
+			req.key = term
+			req.op = selectExists
+		}
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+		if req.key == "" {
+			return nil, fmt.Errorf("invalid selector %q: empty label key", s)
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+// Matches returns true when labels meet every requirement of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.key]
+		var match bool
+		switch req.op {
+		case selectEquals:
+			match = ok && value == req.value
+		case selectNotEquals:
+			match = !ok || value != req.value
+		case selectExists:
+			match = ok
+		case selectNotExists:
+			match = !ok
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectMachines returns the running machines named by hostnames, or the ones
+// matching a label selector, or all the running machines. Named machines not
+// running are an error, other machines not running are skipped.
+func (c *Cluster) SelectMachines(all bool, selector string, hostnames []string) ([]*Machine, error) {
+	given := 0
+	for _, set := range []bool{all, selector != "", len(hostnames) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, errors.New("select machines with either --all, --selector or hostnames")
+	}
+
+	var machines []*Machine
+	if len(hostnames) > 0 {
+		for _, hostname := range hostnames {
+			machine, err := c.machineFromHostname(hostname)
+			if err != nil {
+				return nil, err
+			}
+			if !machine.IsCreated() || !machine.IsStarted() {
+				return nil, fmt.Errorf("%s: machine is not running", hostname)
+			}
+			machines = append(machines, machine)
+		}
+		return machines, nil
+	}
+
+	var sel Selector
+	if selector != "" {
+		var err error
+		if sel, err = ParseSelector(selector); err != nil {
+			return nil, err
+		}
+	}
+	err := c.forEachMachine(func(machine *Machine, _ int) error {
+		if sel.Matches(machine.spec.Labels) && machine.IsCreated() && machine.IsStarted() {
+			machines = append(machines, machine)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(machines) == 0 {
+		return nil, fmt.Errorf("no running machines in cluster %s match", c.spec.Cluster.Name)
+	}
+	return machines, nil
+}
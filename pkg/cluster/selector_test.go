@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{"role": "worker", "zone": "a"}
+	tests := []struct {
+		selector string
+		match    bool
+	}{
+		{"role=worker", true},
+		{"role==worker", true},
+		{"role=controller", false},
+		{"role!=controller", true},
+		{"role!=worker", false},
+		{"zone", true},
+		{"gpu", false},
+		{"!gpu", true},
+		{"!zone", false},
+		{"role=worker, zone=a", true},
+		{"role=worker,zone=b", false},
+		{"gpu!=yes", true},
+	}
+	for _, test := range tests {
+		selector, err := ParseSelector(test.selector)
+		require.NoError(t, err, test.selector)
+		assert.Equal(t, test.match, selector.Matches(labels), test.selector)
+	}
+
+	for _, selector := range []string{"", "=worker", "role=worker,", "!"} {
+		_, err := ParseSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
@@ -0,0 +1,286 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
+)
+
+const (
+	// sshConnectTimeout is how long to keep trying to connect to a machine,
+	// sshd taking a moment to start after the container creation.
+	sshConnectTimeout = 2 * time.Minute
+	sshRetryInterval  = 200 * time.Millisecond
+)
+
+// SSHClient is an SSH connection to a machine.
+type SSHClient struct {
+	*ssh.Client
+	machine *Machine
+	// bastion connection, when jumping through one
+	bastion *ssh.Client
+	// agent connection signing the logins, when authenticating with the ssh
+	// agent
+	agent io.Closer
+}
+
+// retryableSSHError returns true for the errors of connecting to a machine
+// whose sshd isn't ready yet.
+func retryableSSHError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe)
+}
+
+// dialAgent connects to the running ssh agent. The connection has to stay
+// open for its keys to sign, and is closed with the returned closer.
+func dialAgent() (agent.ExtendedAgent, io.Closer, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, nil, errors.New("no ssh agent is running, SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the ssh agent: %w", err)
+	}
+	return agent.NewClient(conn), conn, nil
+}
+
+// agentSigner returns the key of the ssh agent with the cluster agentKey
+// fingerprint, and the closer of the agent connection it signs through.
+func (c *Cluster) agentSigner() (ssh.Signer, io.Closer, error) {
+	client, conn, err := dialAgent()
+	if err != nil {
+		return nil, nil, err
+	}
+	signers, err := client.Signers()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to list the ssh agent keys: %w", err)
+	}
+	for _, signer := range signers {
+		if ssh.FingerprintSHA256(signer.PublicKey()) == c.spec.Cluster.AgentKey {
+			return signer, conn, nil
+		}
+	}
+	conn.Close()
+	return nil, nil, fmt.Errorf("the ssh agent has no key %s", c.spec.Cluster.AgentKey)
+}
+
+// sshAuthMethods returns the cluster private key or agent key, or all the
+// keys of the ssh agent when the cluster has neither. The closer, nil without
+// an agent, has to be called once done with the authentication methods.
+func (c *Cluster) sshAuthMethods() ([]ssh.AuthMethod, io.Closer, error) {
+	if c.spec.Cluster.AgentKey != "" {
+		signer, conn, err := c.agentSigner()
+		if err != nil {
+			return nil, nil, err
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, conn, nil
+	}
+	path, err := c.privateKeyPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	if path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil, nil
+	}
+	client, conn, err := dialAgent()
+	if err != nil {
+		return nil, nil, fmt.Errorf("the cluster has no private key: %w", err)
+	}
+	return []ssh.AuthMethod{ssh.PublicKeysCallback(client.Signers)}, conn, nil
+}
+
+// SSHClient connects to a machine as user, retrying until its SSH server
+// accepts connections or ctx is done.
+func (c *Cluster) SSHClient(ctx context.Context, hostname, user string) (*SSHClient, error) {
+	machine, err := c.machineFromHostname(hostname)
+	if err != nil {
+		return nil, err
+	}
+	if !machine.IsCreated() || !machine.IsStarted() {
+		return nil, fmt.Errorf("%s: machine is not running", hostname)
+	}
+	auth, agentConn, err := c.sshAuthMethods()
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.sshClient(ctx, machine, user, auth)
+	if err != nil {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		return nil, err
+	}
+	client.agent = agentConn
+	return client, nil
+}
+
+// sshClient connects to a running machine as user with the given
+// authentication methods.
+func (c *Cluster) sshClient(ctx context.Context, machine *Machine, user string, auth []ssh.AuthMethod) (*SSHClient, error) {
+	hostname := machine.Hostname()
+	route, err := c.sshRoute(machine)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := c.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ClientConfig{
+		User:              user,
+		Auth:              auth,
+		HostKeyCallback:   hostKeyCallback,
+		HostKeyAlgorithms: []string{hostKeyAlgorithm},
+		Timeout:           10 * time.Second,
+	}
+	addr := route.addr()
+
+	ctx, cancel := context.WithTimeout(ctx, sshConnectTimeout)
+	defer cancel()
+	for {
+		log.Debugf("Connecting to %s@%s (%s) ...", user, addr, route.connection)
+		client, bastion, err := dialSSH(route, machine, config)
+		if err == nil {
+			return &SSHClient{Client: client, machine: machine, bastion: bastion}, nil
+		}
+		if !retryableSSHError(err) {
+			return nil, fmt.Errorf("ssh connection to %s failed: %w", hostname, err)
+		}
+		log.Debugf("Connection to %s failed, retrying: %v", addr, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("ssh connection to %s failed: %s: %w", hostname, ctx.Err(), err)
+		case <-time.After(sshRetryInterval):
+		}
+	}
+}
+
+// Close closes the connection to the machine, and to the bastion and the ssh
+// agent if any.
+func (s *SSHClient) Close() error {
+	err := s.Client.Close()
+	if s.bastion != nil {
+		_ = s.bastion.Close()
+	}
+	if s.agent != nil {
+		_ = s.agent.Close()
+	}
+	return err
+}
+
+// Run runs a command on the machine, or a login shell without terminal when
+// command is empty. A command exiting with a non-zero status returns an
+// *ssh.ExitError.
+func (s *SSHClient) Run(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := s.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if command == "" {
+		if err := session.Shell(); err != nil {
+			return err
+		}
+		return session.Wait()
+	}
+	return session.Run(command)
+}
+
+// Output runs a command on the machine and returns its standard output.
+func (s *SSHClient) Output(command string) ([]byte, error) {
+	session, err := s.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.Output(command)
+}
+
+// Terminal runs a command, or a login shell when command is empty, in a
+// pseudo terminal attached to the local terminal, following its size.
+func (s *SSHClient) Terminal(command string, stdin *os.File, stdout, stderr io.Writer) error {
+	session, err := s.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	fd := int(stdin.Fd())
+	width, height := 80, 24
+	if term.IsTerminal(fd) {
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+	}
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = "xterm-256color"
+	}
+	modes := ssh.TerminalModes{ssh.ECHO: 1, ssh.TTY_OP_ISPEED: 14400, ssh.TTY_OP_OSPEED: 14400}
+	if err := session.RequestPty(termType, height, width, modes); err != nil {
+		return err
+	}
+	stop := watchTerminalSize(fd, func(width, height int) {
+		_ = session.WindowChange(height, width)
+	})
+	defer stop()
+
+	if command == "" {
+		if err := session.Shell(); err != nil {
+			return err
+		}
+		return session.Wait()
+	}
+	return session.Run(command)
+}
+
+// ExitStatus returns the exit status of a command run on machines, and
+// whether err is such an exit status.
+func ExitStatus(err error) (int, bool) {
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), true
+	}
+	var execErr *ExecError
+	if errors.As(err, &execErr) {
+		return execErr.status, true
+	}
+	return 0, false
+}
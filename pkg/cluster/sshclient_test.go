@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestRetryableSSHError(t *testing.T) {
+	assert.True(t, retryableSSHError(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}))
+	assert.True(t, retryableSSHError(fmt.Errorf("ssh: handshake failed: %w", io.EOF)))
+	assert.True(t, retryableSSHError(fmt.Errorf("ssh: handshake failed: %w", &net.OpError{Op: "read", Err: syscall.ECONNRESET})))
+	assert.False(t, retryableSSHError(fmt.Errorf("ssh: handshake failed: ssh: unable to authenticate")))
+}
+
+// startSSHServer starts an SSH server answering exec requests with the
+// command as output and its length as exit status, and shell requests as if
+// the command was "login shell".
+func startSSHServer(t *testing.T) string {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range chans {
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						return
+					}
+					go func() {
+						defer channel.Close()
+						for req := range requests {
+							var command string
+							switch req.Type {
+							case "exec":
+								command = string(req.Payload[4:])
+							case "shell":
+								command = "login shell"
+							default:
+								_ = req.Reply(false, nil)
+								continue
+							}
+							_ = req.Reply(true, nil)
+							_, _ = io.WriteString(channel, command)
+							status := make([]byte, 4)
+							binary.BigEndian.PutUint32(status, uint32(len(command)%256))
+							_, _ = channel.SendRequest("exit-status", false, status)
+							return
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestSSHClientRun(t *testing.T) {
+	addr := startSSHServer(t)
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{User: "root", HostKeyCallback: ssh.InsecureIgnoreHostKey()})
+	require.NoError(t, err)
+	s := &SSHClient{Client: client}
+	defer s.Close()
+
+	out, err := s.Output("")
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	err = s.Run("true", nil, io.Discard, nil)
+	status, ok := ExitStatus(err)
+	require.True(t, ok)
+	assert.Equal(t, 4, status)
+
+	var buf bytes.Buffer
+	err = s.Run("", nil, &buf, nil)
+	status, ok = ExitStatus(err)
+	require.True(t, ok)
+	assert.Equal(t, 11, status)
+	assert.Equal(t, "login shell", buf.String())
+
+	_, ok = ExitStatus(io.EOF)
+	assert.False(t, ok)
+}
+
+// startAgent serves a keyring holding a new key at SSH_AUTH_SOCK and returns
+// the key.
+func startAgent(t *testing.T) ssh.PublicKey {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: priv}))
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	t.Setenv("SSH_AUTH_SOCK", socket)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = agent.ServeAgent(keyring, conn) }()
+		}
+	}()
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+	return signer.PublicKey()
+}
+
+func TestAgentKey(t *testing.T) {
+	key := startAgent(t)
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{AgentKey: ssh.FingerprintSHA256(key)}}}
+	machine := &Machine{spec: &config.Machine{}}
+
+	pub, err := c.publicKey(machine)
+	require.NoError(t, err)
+	assert.Equal(t, ssh.MarshalAuthorizedKey(key), pub)
+	auth, conn, err := c.sshAuthMethods()
+	require.NoError(t, err)
+	assert.Len(t, auth, 1)
+	require.NoError(t, conn.Close())
+
+	signer, conn, err := c.agentSigner()
+	require.NoError(t, err)
+	_, err = signer.Sign(rand.Reader, []byte("data"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	_, err = signer.Sign(rand.Reader, []byte("data"))
+	assert.Error(t, err, "signing through a closed agent connection")
+
+	c.spec.Cluster.AgentKey = "SHA256:missing"
+	_, err = c.publicKey(machine)
+	assert.ErrorContains(t, err, "the ssh agent has no key SHA256:missing")
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	_, _, err = c.sshAuthMethods()
+	assert.ErrorContains(t, err, "SSH_AUTH_SOCK is not set")
+}
@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultSSHConfig is the file the Host blocks are written to when including
+// them from the OpenSSH client config without a configured path.
+const DefaultSSHConfig = "~/.ssh/bootloose_config"
+
+// sshConfigUser is the user the Host blocks log in as, the one the public key
+// is authorized for.
+const sshConfigUser = "root"
+
+// sshHostName returns the address to reach the machine SSH port at from the
+// host.
+func sshHostName(address string) string {
+	address = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	switch address {
+	case "", "0.0.0.0", "::":
+		return "localhost"
+	}
+	return address
+}
+
+// sshEndpoint returns the host address and port the machine SSH port is
+// mapped to.
+func sshEndpoint(machine *Machine) (string, int, error) {
+	mapping, err := mappingFromPort(machine.spec, 22)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: port 22 is not mapped to the host", machine.ContainerName())
+	}
+	port, err := machine.HostPort(22)
+	if err != nil {
+		return "", 0, err
+	}
+	return sshHostName(mapping.Address), port, nil
+}
+
+// privateKeyPath returns the absolute path of the cluster private key, if any.
+func (c *Cluster) privateKeyPath() (string, error) {
+	path, err := expandHomedir(c.spec.Cluster.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand private key path: %w", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+	return filepath.Abs(path)
+}
+
+// writeSSHHost writes the Host block of a machine, checking its host key
+// against the cluster known_hosts file.
+func writeSSHHost(w io.Writer, machine *Machine, route *sshRoute, user, identity, knownHosts string) {
+	// Only the container name is unique among the blocks of all clusters.
+	fmt.Fprintf(w, "Host %s\n", machine.ContainerName())
+	fmt.Fprintf(w, "  HostName %s\n", route.host)
+	fmt.Fprintf(w, "  Port %d\n", route.port)
+	fmt.Fprintf(w, "  User %s\n", user)
+	switch route.connection {
+	case SSHConnectionBastion:
+		fmt.Fprintf(w, "  ProxyJump %s@%s\n", sshConfigUser, route.bastion.ContainerName())
+	case SSHConnectionDocker:
+		fmt.Fprintf(w, "  ProxyCommand %s\n", sshProxyCommand(machine))
+	}
+	if identity != "" {
+		fmt.Fprintf(w, "  IdentityFile %q\n", identity)
+		fmt.Fprintf(w, "  IdentitiesOnly yes\n")
+	}
+	fmt.Fprintf(w, "  UserKnownHostsFile %q\n", knownHosts)
+	fmt.Fprintf(w, "  StrictHostKeyChecking yes\n")
+	fmt.Fprintf(w, "  HostKeyAlgorithms %s\n", hostKeyAlgorithm)
+	fmt.Fprintf(w, "  LogLevel ERROR\n\n")
+}
+
+// SSHConfig writes OpenSSH client config Host blocks for the cluster machines.
+// Each machine can be reached by its container name, eg. 'ssh cluster-node0',
+// following the cluster SSH connection. Machines not running are listed as
+// comments.
+func (c *Cluster) SSHConfig(w io.Writer, user string) error {
+	if user == "" {
+		user = sshConfigUser
+	}
+	identity, err := c.privateKeyPath()
+	if err != nil {
+		return err
+	}
+	knownHosts, err := c.KnownHostsPath()
+	if err != nil {
+		return err
+	}
+	return c.forEachMachine(func(machine *Machine, _ int) error {
+		if !machine.IsCreated() || !machine.IsStarted() {
+			fmt.Fprintf(w, "# %s: machine is not running\n\n", machine.ContainerName())
+			return nil
+		}
+		route, err := c.sshRoute(machine)
+		if err != nil {
+			fmt.Fprintf(w, "# %s: %v\n\n", machine.ContainerName(), err)
+			return nil
+		}
+		writeSSHHost(w, machine, route, user, identity, knownHosts)
+		return nil
+	})
+}
+
+func (c *Cluster) sshConfigBegin() string {
+	return f("# BEGIN bootloose cluster %s", c.spec.Cluster.Name)
+}
+
+func (c *Cluster) sshConfigEnd() string {
+	return f("# END bootloose cluster %s", c.spec.Cluster.Name)
+}
+
+// replaceSSHConfigBlock replaces the cluster block of an ssh config file
+// content, or removes it when block is nil.
+func (c *Cluster) replaceSSHConfigBlock(content, block []byte) []byte {
+	var out bytes.Buffer
+	skip := false
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == c.sshConfigBegin():
+			skip = true
+		case trimmed == c.sshConfigEnd() && skip:
+			skip = false
+		case !skip:
+			out.WriteString(line)
+		}
+	}
+	if block != nil {
+		if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+			out.WriteByte('\n')
+		}
+		fmt.Fprintln(&out, c.sshConfigBegin())
+		out.Write(block)
+		fmt.Fprintln(&out, c.sshConfigEnd())
+	}
+	return out.Bytes()
+}
+
+// SSHConfigPath returns the file the Host blocks are included from, the
+// configured one or DefaultSSHConfig.
+func (c *Cluster) SSHConfigPath() string {
+	if c.spec.Cluster.SSHConfig != "" {
+		return c.spec.Cluster.SSHConfig
+	}
+	return DefaultSSHConfig
+}
+
+// WriteSSHConfigInclude writes the cluster Host blocks to path, keeping the
+// blocks of other clusters, and includes path from ~/.ssh/config.
+func (c *Cluster) WriteSSHConfigInclude(path, user string) error {
+	var block bytes.Buffer
+	if err := c.SSHConfig(&block, user); err != nil {
+		return err
+	}
+	if err := c.updateSSHConfigFile(path, block.Bytes()); err != nil {
+		return err
+	}
+	return includeSSHConfig(path)
+}
+
+// updateSSHConfigFile replaces the cluster block of the ssh config file at
+// path, removing it when block is nil.
+func (c *Cluster) updateSSHConfigFile(path string, block []byte) error {
+	path, err := expandHomedir(path)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, c.replaceSSHConfigBlock(content, block), 0o600)
+}
+
+// includeSSHConfig adds an Include directive for path at the top of
+// ~/.ssh/config, where it applies to every host, unless already present.
+func includeSSHConfig(path string) error {
+	path, err := expandHomedir(path)
+	if err != nil {
+		return err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return err
+	}
+	userConfig, err := expandHomedir("~/.ssh/config")
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(userConfig)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	include := f("Include %q", path)
+	for _, line := range strings.Split(string(content), "\n") {
+		keyword, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if strings.EqualFold(keyword, "Include") && strings.Trim(strings.TrimSpace(value), `"`) == path {
+			return nil
+		}
+	}
+	log.Infof("Adding %s to %s", include, userConfig)
+	if err := os.MkdirAll(filepath.Dir(userConfig), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(userConfig, append([]byte(include+"\n\n"), content...), 0o600)
+}
+
+// updateSSHConfig refreshes the cluster Host blocks in the configured ssh
+// config file, if any.
+func (c *Cluster) updateSSHConfig() error {
+	if c.spec.Cluster.SSHConfig == "" {
+		return nil
+	}
+	log.Infof("Updating the cluster Host blocks in %s ...", c.spec.Cluster.SSHConfig)
+	return c.WriteSSHConfigInclude(c.spec.Cluster.SSHConfig, "")
+}
+
+// removeSSHConfig removes the cluster Host blocks from the configured ssh
+// config file, if any.
+func (c *Cluster) removeSSHConfig() error {
+	if c.spec.Cluster.SSHConfig == "" {
+		return nil
+	}
+	log.Infof("Removing the cluster Host blocks from %s ...", c.spec.Cluster.SSHConfig)
+	return c.updateSSHConfigFile(c.spec.Cluster.SSHConfig, nil)
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSSHHost(t *testing.T) {
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+`))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	machine := cluster.machine(cluster.spec.Machines[0].Spec, 0)
+	route := &sshRoute{connection: SSHConnectionPort, host: sshHostName("0.0.0.0"), port: 2222}
+	writeSSHHost(&buf, machine, route, "root", "/tmp/cluster-key", "/tmp/known_hosts")
+	assert.Equal(t, `Host cluster-node0
+  HostName localhost
+  Port 2222
+  User root
+  IdentityFile "/tmp/cluster-key"
+  IdentitiesOnly yes
+  UserKnownHostsFile "/tmp/known_hosts"
+  StrictHostKeyChecking yes
+  HostKeyAlgorithms ssh-ed25519
+  LogLevel ERROR
+
+`, buf.String())
+	assert.Equal(t, "::1", sshHostName("[::1]"))
+
+	buf.Reset()
+	route = &sshRoute{connection: SSHConnectionBastion, host: "172.17.0.3", port: 22, bastion: cluster.machine(cluster.spec.Machines[0].Spec, 1)}
+	writeSSHHost(&buf, machine, route, "k0s", "", "/tmp/known_hosts")
+	assert.Contains(t, buf.String(), "  HostName 172.17.0.3\n  Port 22\n  User k0s\n  ProxyJump root@cluster-node1\n  UserKnownHostsFile")
+
+	buf.Reset()
+	route = &sshRoute{connection: SSHConnectionDocker, host: "cluster-node0", port: 22}
+	writeSSHHost(&buf, machine, route, "root", "", "/tmp/known_hosts")
+	assert.Contains(t, buf.String(), "  HostName cluster-node0\n  Port 22\n  User root\n  ProxyCommand docker exec -i cluster-node0 /bin/sh -c 'if command -v socat")
+}
+
+func TestUpdateSSHConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cluster, err := NewFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 1
+  spec:
+    image: quay.io/k0sproject/bootloose-ubuntu20.04:latest
+    name: node%d
+`))
+	require.NoError(t, err)
+
+	path := "~/.ssh/bootloose_config"
+	file := filepath.Join(os.Getenv("HOME"), ".ssh", "bootloose_config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o700))
+	other := "# BEGIN bootloose cluster other\nHost other-node0\n# END bootloose cluster other\n"
+	require.NoError(t, os.WriteFile(file, []byte(other), 0o600))
+
+	require.NoError(t, cluster.updateSSHConfigFile(path, []byte("Host old\n")))
+	require.NoError(t, cluster.updateSSHConfigFile(path, []byte("Host new\n")))
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, other+"# BEGIN bootloose cluster cluster\nHost new\n# END bootloose cluster cluster\n", string(content))
+
+	require.NoError(t, cluster.updateSSHConfigFile(path, nil))
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, other, string(content))
+
+	userConfig := filepath.Join(os.Getenv("HOME"), ".ssh", "config")
+	require.NoError(t, os.WriteFile(userConfig, []byte("Host *\n  ForwardAgent no\n"), 0o600))
+	require.NoError(t, includeSSHConfig(path))
+	require.NoError(t, includeSSHConfig(path))
+	content, err = os.ReadFile(userConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "Include \""+file+"\"\n\nHost *\n  ForwardAgent no\n", string(content))
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSH connections, how the machines SSH server is reached.
+const (
+	SSHConnectionAuto    = "auto"
+	SSHConnectionPort    = "port"
+	SSHConnectionDirect  = "direct"
+	SSHConnectionBastion = "bastion"
+	SSHConnectionDocker  = "docker"
+)
+
+// sshRoute is how to reach the SSH server of a machine.
+type sshRoute struct {
+	connection string
+	// host and port dialed, from the host or from the bastion. The host key
+	// is looked up by them.
+	host string
+	port int
+	// bastion and its own route, with the bastion connection
+	bastion      *Machine
+	bastionRoute *sshRoute
+}
+
+// addr returns the address dialed.
+func (r sshRoute) addr() string {
+	return net.JoinHostPort(r.host, strconv.Itoa(r.port))
+}
+
+// sshConnection returns the SSH connection used for a machine, resolving the
+// auto connection.
+func (c *Cluster) sshConnection(machine *Machine) string {
+	connection := c.spec.Cluster.SSHConnection
+	if connection != "" && connection != SSHConnectionAuto {
+		return connection
+	}
+	if _, err := mappingFromPort(machine.spec, 22); err == nil {
+		return SSHConnectionPort
+	}
+	if runtime.GOOS == "linux" && !remoteDocker() {
+		return SSHConnectionDirect
+	}
+	if c.spec.Cluster.SSHBastion != "" {
+		return SSHConnectionBastion
+	}
+	return SSHConnectionDocker
+}
+
+// sshRoute returns how to reach the SSH server of a running machine. The
+// bastion itself is reached through its mapped port.
+func (c *Cluster) sshRoute(machine *Machine) (*sshRoute, error) {
+	connection := c.sshConnection(machine)
+	if connection == SSHConnectionBastion && machine.Hostname() == c.spec.Cluster.SSHBastion {
+		connection = SSHConnectionPort
+	}
+	route := &sshRoute{connection: connection, host: machine.ContainerName(), port: 22}
+	switch connection {
+	case SSHConnectionPort:
+		host, port, err := sshEndpoint(machine)
+		if err != nil {
+			return nil, err
+		}
+		route.host, route.port = host, port
+	case SSHConnectionDirect, SSHConnectionBastion:
+		if err := machine.loadAddresses(); err != nil {
+			return nil, err
+		}
+		route.host = machine.ip
+	}
+	if connection == SSHConnectionBastion {
+		bastion, err := c.machineFromHostname(c.spec.Cluster.SSHBastion)
+		if err != nil {
+			return nil, err
+		}
+		if !bastion.IsCreated() || !bastion.IsStarted() {
+			return nil, fmt.Errorf("%s: bastion machine is not running", bastion.Hostname())
+		}
+		if route.bastionRoute, err = c.sshRoute(bastion); err != nil {
+			return nil, err
+		}
+		route.bastion = bastion
+	}
+	return route, nil
+}
+
+// dialSSH connects to the SSH server of a machine following its route. The
+// bastion client, if any, has to be closed along with the returned one.
+func dialSSH(route *sshRoute, machine *Machine, config *ssh.ClientConfig) (*ssh.Client, *ssh.Client, error) {
+	addr := route.addr()
+	switch route.connection {
+	case SSHConnectionBastion:
+		// The public key is authorized for root on the bastion.
+		bastionConfig := *config
+		bastionConfig.User = sshConfigUser
+		bastion, _, err := dialSSH(route.bastionRoute, route.bastion, &bastionConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bastion %s: %w", route.bastion.Hostname(), err)
+		}
+		conn, err := bastion.Dial("tcp", addr)
+		if err != nil {
+			bastion.Close()
+			return nil, nil, err
+		}
+		client, err := newSSHClient(conn, addr, config)
+		if err != nil {
+			bastion.Close()
+			return nil, nil, err
+		}
+		return client, bastion, nil
+	case SSHConnectionDocker:
+		local, remote := net.Pipe()
+		forwarder := &portForwarder{machine: machine, exec: true}
+		go func() {
+			defer remote.Close()
+			if err := forwarder.execForward(remote, "TCP", 22); err != nil {
+				log.Debugf("%s: docker exec tunnel: %v", machine.Hostname(), err)
+			}
+		}()
+		client, err := newSSHClient(local, addr, config)
+		return client, nil, err
+	default:
+		client, err := ssh.Dial("tcp", addr, config)
+		return client, nil, err
+	}
+}
+
+// newSSHClient runs the SSH handshake over conn, closing it on failure.
+func newSSHClient(conn net.Conn, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// sshProxyCommand is the OpenSSH ProxyCommand tunneling to a machine SSH
+// server through docker exec.
+func sshProxyCommand(machine *Machine) string {
+	return f("docker exec -i %s /bin/sh -c %s", machine.ContainerName(), shellQuote(execForwardScript("TCP", 22)))
+}
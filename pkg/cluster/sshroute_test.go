@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestSSHConnection(t *testing.T) {
+	mapped := &config.Machine{Name: "node%d", PortMappings: []config.PortMapping{{ContainerPort: 22}}}
+	internal := &config.Machine{Name: "worker%d"}
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{Name: "cluster"}}}
+
+	assert.Equal(t, SSHConnectionPort, c.sshConnection(c.machine(mapped, 0)))
+
+	t.Setenv("DOCKER_HOST", "")
+	if runtime.GOOS == "linux" {
+		assert.Equal(t, SSHConnectionDirect, c.sshConnection(c.machine(internal, 0)))
+	}
+	t.Setenv("DOCKER_HOST", "tcp://docker.example.com:2376")
+	assert.Equal(t, SSHConnectionDocker, c.sshConnection(c.machine(internal, 0)))
+	c.spec.Cluster.SSHBastion = "node0"
+	assert.Equal(t, SSHConnectionBastion, c.sshConnection(c.machine(internal, 0)))
+
+	c.spec.Cluster.SSHConnection = SSHConnectionDocker
+	assert.Equal(t, SSHConnectionDocker, c.sshConnection(c.machine(mapped, 0)))
+}
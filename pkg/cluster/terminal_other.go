@@ -0,0 +1,11 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !unix
+
+package cluster
+
+// watchTerminalSize is a no-op where terminals don't signal size changes.
+func watchTerminalSize(int, func(width, height int)) func() {
+	return func() {}
+}
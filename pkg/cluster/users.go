@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+	"github.com/k0sproject/bootloose/pkg/docker"
+	"github.com/k0sproject/bootloose/pkg/exec"
+)
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// machineUsers returns the users of a machine, the cluster users followed by
+// the machine ones, the latter replacing cluster users of the same name.
+func (c *Cluster) machineUsers(machine *Machine) []config.User {
+	var users []config.User
+	own := map[string]bool{}
+	for _, user := range machine.spec.Users {
+		own[user.Name] = true
+	}
+	for _, user := range c.spec.Cluster.Users {
+		if !own[user.Name] {
+			users = append(users, user)
+		}
+	}
+	return append(users, machine.spec.Users...)
+}
+
+// userScript creates a user unless it already exists, with useradd or with
+// busybox adduser. The password is set to '*' rather than left locked, sshd
+// refusing locked accounts when not using PAM.
+func userScript(user config.User, defaultKeys []byte) string {
+	name := shellQuote(user.Name)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "set -e\nif grep -q '^%s:' /etc/passwd; then\n", user.Name)
+	fmt.Fprintf(&buf, "  echo 'User %s already exists, skipping'\n  exit 0\nfi\n", user.Name)
+
+	for _, group := range user.Groups {
+		fmt.Fprintf(&buf, "grep -q '^%s:' /etc/group || groupadd %s 2>/dev/null || addgroup %s\n", group, group, group)
+	}
+	var useradd, adduser []string
+	if user.UID != 0 {
+		useradd = append(useradd, "--uid", f("%d", user.UID))
+		adduser = append(adduser, "-u", f("%d", user.UID))
+	}
+	if user.Shell != "" {
+		useradd = append(useradd, "--shell", shellQuote(user.Shell))
+		adduser = append(adduser, "-s", shellQuote(user.Shell))
+	}
+	if len(user.Groups) > 0 {
+		useradd = append(useradd, "--groups", strings.Join(user.Groups, ","))
+	}
+	fmt.Fprintf(&buf, "if command -v useradd >/dev/null; then\n")
+	fmt.Fprintf(&buf, "  useradd --create-home --password '*' %s\n", strings.Join(append(useradd, name), " "))
+	fmt.Fprintf(&buf, "else\n")
+	fmt.Fprintf(&buf, "  adduser -D %s\n", strings.Join(append(adduser, name), " "))
+	for _, group := range user.Groups {
+		fmt.Fprintf(&buf, "  addgroup %s %s\n", name, group)
+	}
+	fmt.Fprintf(&buf, "  sed -i 's/^%s:!*:/%s:*:/' /etc/shadow\n", user.Name, user.Name)
+	fmt.Fprintf(&buf, "fi\n")
+
+	keys := string(defaultKeys)
+	if len(user.AuthorizedKeys) > 0 {
+		keys = strings.Join(user.AuthorizedKeys, "\n")
+	}
+	if !strings.HasSuffix(keys, "\n") {
+		keys += "\n"
+	}
+	fmt.Fprintf(&buf, "home=$(grep '^%s:' /etc/passwd | cut -d: -f6)\n", user.Name)
+	fmt.Fprintf(&buf, "mkdir -p \"$home/.ssh\"\n")
+	fmt.Fprintf(&buf, "cat > \"$home/.ssh/authorized_keys\" <<'__EOF'\n%s__EOF\n", keys)
+	fmt.Fprintf(&buf, "chmod 700 \"$home/.ssh\"\nchmod 600 \"$home/.ssh/authorized_keys\"\n")
+	fmt.Fprintf(&buf, "chown -R %s: \"$home/.ssh\"\n", name)
+
+	if user.Sudo {
+		fmt.Fprintf(&buf, "command -v sudo >/dev/null || echo 'sudo is not installed, %s cannot use it'\n", user.Name)
+		fmt.Fprintf(&buf, "mkdir -p /etc/sudoers.d\n")
+		fmt.Fprintf(&buf, "echo '%s ALL=(ALL) NOPASSWD:ALL' > /etc/sudoers.d/bootloose-%s\n", user.Name, user.Name)
+		fmt.Fprintf(&buf, "chmod 440 /etc/sudoers.d/bootloose-%s\n", user.Name)
+	}
+	return buf.String()
+}
+
+// createUsers creates the users of a machine, authorizing publicKey unless
+// they have their own authorized keys.
+func (c *Cluster) createUsers(machine *Machine, publicKey []byte) error {
+	name := machine.ContainerName()
+	for _, user := range c.machineUsers(machine) {
+		log.Infof("Creating user %s on %s ...", user.Name, name)
+		cmd := docker.ContainerCmder(name).Command("/bin/sh", "-c", userScript(user, publicKey))
+		output, err := exec.CombinedOutputLines(cmd)
+		for _, line := range output {
+			if err != nil {
+				log.WithField("machine", name).Error(line)
+			} else {
+				log.WithField("machine", name).Info(line)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create user %s on %s: %w", user.Name, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/k0sproject/bootloose/pkg/config"
+)
+
+func TestMachineUsers(t *testing.T) {
+	c := &Cluster{spec: config.Config{Cluster: config.Cluster{Users: []config.User{
+		{Name: "k0s", Sudo: true},
+		{Name: "alice"},
+	}}}}
+	machine := &Machine{spec: &config.Machine{Users: []config.User{{Name: "k0s"}}}}
+	assert.Equal(t, []config.User{{Name: "alice"}, {Name: "k0s"}}, c.machineUsers(machine))
+}
+
+func TestUserScript(t *testing.T) {
+	script := userScript(config.User{Name: "k0s", UID: 1500, Groups: []string{"docker"}, Shell: "/bin/bash", Sudo: true}, []byte("ssh-ed25519 AAAA root-key\n"))
+	assert.Contains(t, script, "if grep -q '^k0s:' /etc/passwd; then")
+	assert.Contains(t, script, "useradd --create-home --password '*' --uid 1500 --shell '/bin/bash' --groups docker 'k0s'\n")
+	assert.Contains(t, script, "adduser -D -u 1500 -s '/bin/bash' 'k0s'\n  addgroup 'k0s' docker\n")
+	assert.Contains(t, script, "<<'__EOF'\nssh-ed25519 AAAA root-key\n__EOF\n")
+	assert.Contains(t, script, "echo 'k0s ALL=(ALL) NOPASSWD:ALL' > /etc/sudoers.d/bootloose-k0s\n")
+
+	script = userScript(config.User{Name: "alice", AuthorizedKeys: []string{"ssh-ed25519 BBBB alice"}}, []byte("ssh-ed25519 AAAA root-key\n"))
+	assert.Contains(t, script, "<<'__EOF'\nssh-ed25519 BBBB alice\n__EOF\n")
+	assert.NotContains(t, script, "root-key")
+	assert.NotContains(t, script, "sudoers")
+}
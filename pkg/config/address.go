@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"text/template"
+)
+
+// NetworkAddress assigns static IP addresses to the machines of a template on
+// one of their networks. The network needs to have a user configured subnet.
+type NetworkAddress struct {
+	// Network is the name of the network, it has to be one of the machine
+	// networks.
+	Network string `json:"network"`
+	// IPv4Address is a template rendered for every machine to get its address,
+	// eg. "172.30.0.{{add 10 .Index}}". .Index is the machine index, the number
+	// used in the machine name.
+	IPv4Address string `json:"ipv4Address,omitempty"`
+	// IPv4Start is the address of the first machine of the template, the next
+	// machines use the following addresses.
+	IPv4Start string `json:"ipv4Start,omitempty"`
+}
+
+var addressFuncs = template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+	"sub": func(a, b int) int { return a - b },
+	"mul": func(a, b int) int { return a * b },
+}
+
+// validate checks basic rules for NetworkAddress's fields
+func (conf NetworkAddress) validate() error {
+	if conf.Network == "" {
+		return errors.New("network is required")
+	}
+	switch {
+	case conf.IPv4Address == "" && conf.IPv4Start == "":
+		return errors.New("one of ipv4Address and ipv4Start is required")
+	case conf.IPv4Address != "" && conf.IPv4Start != "":
+		return errors.New("ipv4Address and ipv4Start cannot be used together")
+	case conf.IPv4Start != "":
+		if ip := net.ParseIP(conf.IPv4Start); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("invalid ipv4Start %q", conf.IPv4Start)
+		}
+	default:
+		if _, err := template.New("").Funcs(addressFuncs).Parse(conf.IPv4Address); err != nil {
+			return fmt.Errorf("invalid ipv4Address: %w", err)
+		}
+	}
+	return nil
+}
+
+// IPv4 returns the address of the machine with the given index.
+func (conf NetworkAddress) IPv4(index int) (net.IP, error) {
+	if conf.IPv4Start != "" {
+		start := net.ParseIP(conf.IPv4Start).To4()
+		if start == nil {
+			return nil, fmt.Errorf("invalid ipv4Start %q", conf.IPv4Start)
+		}
+		n := binary.BigEndian.Uint32(start) + uint32(index)
+		if n < binary.BigEndian.Uint32(start) {
+			return nil, fmt.Errorf("ipv4Start %s: address %d is out of range", conf.IPv4Start, index)
+		}
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, n)
+		return ip, nil
+	}
+
+	tmpl, err := template.New("").Funcs(addressFuncs).Parse(conf.IPv4Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ipv4Address: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Index int }{index}); err != nil {
+		return nil, fmt.Errorf("invalid ipv4Address: %w", err)
+	}
+	s := strings.TrimSpace(buf.String())
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() == nil {
+		return nil, fmt.Errorf("ipv4Address %q renders to %q, which is not an IPv4 address", conf.IPv4Address, s)
+	}
+	return ip.To4(), nil
+}
+
+// IPv4Address returns the static IPv4 address of the machine with the given
+// index on network, or "" if the address is assigned by docker.
+func (conf *Machine) IPv4Address(network string, index int) (string, error) {
+	for _, address := range conf.Addresses {
+		if address.Network != network {
+			continue
+		}
+		ip, err := address.IPv4(index)
+		if err != nil {
+			return "", err
+		}
+		return ip.String(), nil
+	}
+	return "", nil
+}
+
+// SubnetContains checks whether ip is a usable host address of subnet, which
+// excludes the network and broadcast addresses.
+func SubnetContains(subnet *net.IPNet, ip net.IP) bool {
+	if !subnet.Contains(ip) {
+		return false
+	}
+	ip4, network := ip.To4(), subnet.IP.To4()
+	if ip4 == nil || network == nil {
+		return true
+	}
+	ones, bits := subnet.Mask.Size()
+	if bits-ones < 2 {
+		return true
+	}
+	broadcast := make(net.IP, net.IPv4len)
+	for i := range network {
+		broadcast[i] = network[i] | ^subnet.Mask[len(subnet.Mask)-net.IPv4len+i]
+	}
+	return !ip4.Equal(network) && !ip4.Equal(broadcast)
+}
+
+// validateAddresses checks that the static addresses of all the machines are
+// valid, fit the subnet of the managed networks and don't collide.
+func (conf Config) validateAddresses() []error {
+	var errs []error
+	managed := map[string]bool{}
+	subnets := map[string]*net.IPNet{}
+	gateways := map[string]string{}
+	for _, network := range conf.Cluster.Networks {
+		managed[network.Name] = true
+		if _, subnet, err := net.ParseCIDR(network.Subnet); err == nil {
+			subnets[network.Name] = subnet
+			gateways[network.Name] = network.Gateway
+		}
+	}
+
+	// network -> ip -> machine
+	used := map[string]map[string]string{}
+	for i, machine := range conf.Machines {
+		if machine.Spec == nil {
+			continue
+		}
+		for j, address := range machine.Spec.Addresses {
+			path := fmt.Sprintf("machines[%d].spec.addresses[%d]", i, j)
+			if address.validate() != nil {
+				continue
+			}
+			if managed[address.Network] && subnets[address.Network] == nil {
+				errs = append(errs, fmt.Errorf("%s: network %s needs a subnet to use static addresses", path, address.Network))
+				continue
+			}
+			if used[address.Network] == nil {
+				used[address.Network] = map[string]string{}
+			}
+			for index := 0; index < machine.Count; index++ {
+				name := fmt.Sprintf(machine.Spec.Name, index)
+				ip, err := address.IPv4(index)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+					continue
+				}
+				if subnet := subnets[address.Network]; subnet != nil {
+					if !SubnetContains(subnet, ip) {
+						errs = append(errs, fmt.Errorf("%s: %s: address %s is not in subnet %s", path, name, ip, subnet))
+					}
+					if gateways[address.Network] == ip.String() {
+						errs = append(errs, fmt.Errorf("%s: %s: address %s is the gateway of network %s", path, name, ip, address.Network))
+					}
+				}
+				if other, ok := used[address.Network][ip.String()]; ok {
+					errs = append(errs, fmt.Errorf("%s: %s: address %s on network %s is already used by %s", path, name, ip, address.Network, other))
+					continue
+				}
+				used[address.Network][ip.String()] = name
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkAddressIPv4(t *testing.T) {
+	address := NetworkAddress{Network: "net1", IPv4Address: "172.30.0.{{add 10 .Index}}"}
+	ip, err := address.IPv4(2)
+	require.NoError(t, err)
+	assert.Equal(t, "172.30.0.12", ip.String())
+
+	address = NetworkAddress{Network: "net1", IPv4Start: "172.30.0.254"}
+	ip, err = address.IPv4(3)
+	require.NoError(t, err)
+	assert.Equal(t, "172.30.1.1", ip.String())
+
+	address = NetworkAddress{Network: "net1", IPv4Address: "172.30.0.{{add 250 .Index}}"}
+	_, err = address.IPv4(10)
+	assert.ErrorContains(t, err, `renders to "172.30.0.260", which is not an IPv4 address`)
+}
+
+func TestSubnetContains(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("172.30.0.0/24")
+	require.NoError(t, err)
+	assert.True(t, SubnetContains(subnet, net.ParseIP("172.30.0.1")))
+	assert.True(t, SubnetContains(subnet, net.ParseIP("172.30.0.254")))
+	assert.False(t, SubnetContains(subnet, net.ParseIP("172.30.0.0")))
+	assert.False(t, SubnetContains(subnet, net.ParseIP("172.30.0.255")))
+	assert.False(t, SubnetContains(subnet, net.ParseIP("172.30.1.1")))
+}
+
+func TestConfigValidateAddresses(t *testing.T) {
+	conf, err := NewConfigFromYAML([]byte(`cluster:
+  name: cluster
+  networks:
+  - name: net1
+    subnet: 172.30.0.0/24
+    gateway: 172.30.0.1
+  - name: net2
+machines:
+- count: 3
+  spec:
+    name: controller%d
+    networks: [net1, external]
+    addresses:
+    - network: net1
+      ipv4Address: "172.30.0.{{add 10 .Index}}"
+    - network: external
+      ipv4Start: 10.0.0.10
+- count: 2
+  spec:
+    name: worker%d
+    networks: [net1]
+    addresses:
+    - network: net1
+      ipv4Start: 172.30.0.20
+`))
+	require.NoError(t, err)
+	require.NoError(t, conf.Validate())
+
+	ip, err := conf.Machines[0].Spec.IPv4Address("net1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "172.30.0.11", ip)
+	ip, err = conf.Machines[0].Spec.IPv4Address("net2", 1)
+	require.NoError(t, err)
+	assert.Empty(t, ip)
+
+	conf.Machines[1].Spec.Addresses[0].IPv4Start = "172.30.0.12"
+	assert.ErrorContains(t, conf.Validate(), "worker0: address 172.30.0.12 on network net1 is already used by controller2")
+
+	conf.Machines[1].Spec.Addresses[0].IPv4Start = "172.30.0.254"
+	assert.ErrorContains(t, conf.Validate(), "worker1: address 172.30.0.255 is not in subnet 172.30.0.0/24")
+
+	conf.Machines[1].Spec.Addresses[0].IPv4Start = "172.30.0.1"
+	assert.ErrorContains(t, conf.Validate(), "worker0: address 172.30.0.1 is the gateway of network net1")
+
+	conf.Machines[1].Spec.Networks = []string{"net2"}
+	conf.Machines[1].Spec.Addresses[0] = NetworkAddress{Network: "net2", IPv4Start: "172.31.0.2"}
+	assert.ErrorContains(t, conf.Validate(), "network net2 needs a subnet to use static addresses")
+
+	conf.Machines[1].Spec.Addresses[0] = NetworkAddress{Network: "net1", IPv4Start: "172.30.0.20"}
+	assert.ErrorContains(t, conf.Validate(), "machines[1]: addresses[0]: net1 is not one of the machine networks")
+
+	conf.Machines[1].Spec.Networks = []string{"net1"}
+	conf.Machines[1].Spec.Addresses[0].IPv4Address = "172.30.0.{{.Index}"
+	assert.ErrorContains(t, conf.Validate(), "ipv4Address and ipv4Start cannot be used together")
+	conf.Machines[1].Spec.Addresses[0].IPv4Start = ""
+	assert.ErrorContains(t, conf.Validate(), "invalid ipv4Address")
+}
@@ -6,13 +6,35 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 
-	log "github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
+	"github.com/ghodss/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
 )
 
-func NewConfigFromYAML(data []byte) (*Config, error) {
+// NewConfigFromYAML parses a YAML serialization of a Config. Unless disabled,
+// environment variables and files referenced from string values are
+// interpolated, see WithStrictInterpolation and WithoutInterpolation.
+func NewConfigFromYAML(data []byte, opts ...LoadOption) (*Config, error) {
+	o := newLoadOptions(opts)
+	if o.interpolate {
+		var doc interface{}
+		if err := yamlv2.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		doc, err := o.interpolateValue(doc, reflect.TypeOf(Config{}), "")
+		if err != nil {
+			return nil, err
+		}
+		if data, err = yamlv2.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
 	spec := Config{}
 	if err := yaml.Unmarshal(data, &spec); err != nil {
 		return nil, err
@@ -20,12 +42,16 @@ func NewConfigFromYAML(data []byte) (*Config, error) {
 	return &spec, nil
 }
 
-func NewConfigFromFile(path string) (*Config, error) {
+// NewConfigFromFile parses a YAML serialization of a Config from a file.
+// Relative ${file:path} references are resolved against the directory of the
+// file.
+func NewConfigFromFile(path string, opts ...LoadOption) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return NewConfigFromYAML(data)
+	opts = append([]LoadOption{WithBaseDir(filepath.Dir(path))}, opts...)
+	return NewConfigFromYAML(data, opts...)
 }
 
 // MachineReplicas are a number of machine following the same specification.
@@ -45,6 +71,57 @@ type Cluster struct {
 	// This field is optional. If absent, machines are expected to have a public
 	// key defined.
 	PrivateKey string `json:"privateKey,omitempty"`
+
+	// KeyType is the algorithm of the key pair generated at PrivateKey when
+	// missing: "ed25519", "rsa-3072", "rsa-4096", "ecdsa-p256" or "ecdsa-p384".
+	// Defaults to "ed25519".
+	KeyType string `json:"keyType,omitempty"`
+
+	// AgentKey is the SHA256 fingerprint of a key of the running ssh-agent
+	// used to login into the cluster machines instead of PrivateKey, eg.
+	// "SHA256:9neGyJTu01FsmPO6HfRm6QtgfM2fha3/ysl82LoyqEg". The private key
+	// stays in the agent.
+	AgentKey string `json:"agentKey,omitempty"`
+
+	// Networks are the docker networks bootloose creates with the cluster and
+	// removes when deleting it.
+	Networks []Network `json:"networks,omitempty"`
+
+	// ManageHosts writes the hostname and address of every machine into the
+	// /etc/hosts file of each machine after creating and starting them, so
+	// machines resolve each other even on the default bridge network.
+	ManageHosts bool `json:"manageHosts,omitempty"`
+
+	// Domain is appended to the machine hostnames in the /etc/hosts entries
+	// written when ManageHosts is set, eg. "node0.cluster.local".
+	Domain string `json:"domain,omitempty"`
+
+	// SSHConfig is the path of an OpenSSH client config file the machines Host
+	// blocks are written to when creating and starting the cluster, and removed
+	// from when deleting it. The file is included from ~/.ssh/config.
+	SSHConfig string `json:"sshConfig,omitempty"`
+
+	// KnownHosts is the path of the known_hosts file bootloose records the
+	// machines host keys in, and checks them against when connecting.
+	// Defaults to ~/.ssh/bootloose_known_hosts.
+	KnownHosts string `json:"knownHosts,omitempty"`
+
+	// SSHConnection is how bootloose and the generated OpenSSH config reach
+	// the machines SSH server: "port" through the host port mapped to port 22,
+	// "direct" at the machine address, which Linux hosts can route to,
+	// "bastion" jumping through the SSHBastion machine, or "docker" tunneling
+	// through docker exec with socat or nc. Defaults to "auto", using the
+	// mapped port when there is one, then direct on Linux with a local docker
+	// daemon, then the bastion if any, then docker.
+	SSHConnection string `json:"sshConnection,omitempty"`
+
+	// SSHBastion is the hostname of the machine connections jump through with
+	// the "bastion" SSH connection. Its port 22 has to be mapped to the host.
+	SSHBastion string `json:"sshBastion,omitempty"`
+
+	// Users are the user accounts created on every machine, in addition to the
+	// users of each machine.
+	Users []User `json:"users,omitempty"`
 }
 
 // Config is the top level config object.
@@ -53,29 +130,104 @@ type Config struct {
 	Cluster Cluster `json:"cluster"`
 	// Machines describe the machines we want created for this cluster.
 	Machines []MachineReplicas `json:"machines"`
+	// Faults describe a network fault scenario that can be applied to the
+	// cluster machines.
+	Faults []Fault `json:"faults,omitempty"`
 }
 
 // validate checks basic rules for MachineReplicas's fields
 func (conf MachineReplicas) validate() error {
+	if conf.Spec == nil {
+		return errors.New("spec is required")
+	}
 	return conf.Spec.validate()
 }
 
 // Validate checks basic rules for Config's fields
 func (conf Config) Validate() error {
-	valid := true
+	var errs []error
+	networks := map[string]bool{}
+	for i, network := range conf.Cluster.Networks {
+		if err := network.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("cluster.networks[%d]: %w", i, err))
+		}
+		if networks[network.Name] {
+			errs = append(errs, fmt.Errorf("cluster.networks[%d]: network %q is declared more than once", i, network.Name))
+		}
+		networks[network.Name] = true
+	}
+	if conf.Cluster.Domain != "" && !validDomain(conf.Cluster.Domain) {
+		errs = append(errs, fmt.Errorf("cluster.domain: %q is not a valid domain name", conf.Cluster.Domain))
+	}
+	if err := validateUsers(conf.Cluster.Users); err != nil {
+		errs = append(errs, fmt.Errorf("cluster.%w", err))
+	}
+	switch conf.Cluster.KeyType {
+	case "", "ed25519", "rsa-3072", "rsa-4096", "ecdsa-p256", "ecdsa-p384":
+	default:
+		errs = append(errs, fmt.Errorf("cluster.keyType: unknown key type %q, should be one of ed25519, rsa-3072, rsa-4096, ecdsa-p256 or ecdsa-p384", conf.Cluster.KeyType))
+	}
+	if conf.Cluster.AgentKey != "" {
+		if !strings.HasPrefix(conf.Cluster.AgentKey, "SHA256:") {
+			errs = append(errs, fmt.Errorf("cluster.agentKey: %q is not a SHA256 key fingerprint", conf.Cluster.AgentKey))
+		}
+		if conf.Cluster.PrivateKey != "" {
+			errs = append(errs, errors.New("cluster.agentKey: can't be used along with cluster.privateKey"))
+		}
+	}
+	for i, machine := range conf.Machines {
+		if err := machine.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("machines[%d]: %w", i, err))
+		}
+	}
+	errs = append(errs, conf.validateAddresses()...)
+	hostnames := map[string]bool{}
 	for _, machine := range conf.Machines {
-		err := machine.validate()
-		if err != nil {
-			valid = false
-			log.Fatal(err.Error())
+		if machine.Spec == nil {
+			continue
+		}
+		for i := 0; i < machine.Count; i++ {
+			hostnames[fmt.Sprintf(machine.Spec.Name, i)] = true
+		}
+	}
+	switch conf.Cluster.SSHConnection {
+	case "", "auto", "port", "direct", "docker":
+	case "bastion":
+		if conf.Cluster.SSHBastion == "" {
+			errs = append(errs, errors.New("cluster.sshBastion: a bastion is required by the bastion SSH connection"))
 		}
+	default:
+		errs = append(errs, fmt.Errorf("cluster.sshConnection: unknown connection %q, should be one of auto, port, direct, bastion or docker", conf.Cluster.SSHConnection))
+	}
+	if conf.Cluster.SSHBastion != "" && !hostnames[conf.Cluster.SSHBastion] {
+		errs = append(errs, fmt.Errorf("cluster.sshBastion: %s is not a machine hostname", conf.Cluster.SSHBastion))
 	}
-	if !valid {
-		return errors.New("configuration file non valid")
+	for i, fault := range conf.Faults {
+		if err := fault.validate(hostnames); err != nil {
+			errs = append(errs, fmt.Errorf("faults[%d]: %w", i, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("configuration file non valid: %w", errors.Join(errs...))
 	}
 	return nil
 }
 
+var domainLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// validDomain returns true when domain is made of valid DNS labels.
+func validDomain(domain string) bool {
+	if len(domain) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if !domainLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
 func DefaultConfig() Config {
 	return Config{
 		Cluster: Cluster{
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDomain(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Cluster.Domain = "cluster.local"
+	assert.NoError(t, conf.Validate())
+
+	conf.Cluster.Domain = "-cluster..local"
+	assert.ErrorContains(t, conf.Validate(), `cluster.domain: "-cluster..local" is not a valid domain name`)
+}
+
+func TestConfigValidateSSHConnection(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Cluster.SSHConnection = "bastion"
+	assert.ErrorContains(t, conf.Validate(), "a bastion is required")
+	conf.Cluster.SSHBastion = "node0"
+	assert.NoError(t, conf.Validate())
+	conf.Cluster.SSHBastion = "node1"
+	assert.ErrorContains(t, conf.Validate(), "node1 is not a machine hostname")
+	conf.Cluster.SSHConnection = "telnet"
+	assert.ErrorContains(t, conf.Validate(), `unknown connection "telnet"`)
+}
+
+func TestConfigValidateClusterKey(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Cluster.KeyType = "rsa-4096"
+	assert.NoError(t, conf.Validate())
+
+	conf.Cluster.KeyType = "dsa"
+	assert.ErrorContains(t, conf.Validate(), `cluster.keyType: unknown key type "dsa"`)
+
+	conf.Cluster.KeyType = ""
+	conf.Cluster.AgentKey = "SHA256:9neGyJTu01FsmPO6HfRm6QtgfM2fha3/ysl82LoyqEg"
+	assert.ErrorContains(t, conf.Validate(), "cluster.agentKey: can't be used along with cluster.privateKey")
+
+	conf.Cluster.PrivateKey = ""
+	assert.NoError(t, conf.Validate())
+
+	conf.Cluster.AgentKey = "9neGyJTu01FsmPO6HfRm6QtgfM2fha3"
+	assert.ErrorContains(t, conf.Validate(), "is not a SHA256 key fingerprint")
+}
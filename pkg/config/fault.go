@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shaping is the traffic control applied to the network interfaces of a
+// machine with netem.
+type Shaping struct {
+	// Delay is added to every outgoing packet, eg. "100ms".
+	Delay string `json:"delay,omitempty"`
+	// Jitter is the random variation of the delay, eg. "10ms". Requires a delay.
+	Jitter string `json:"jitter,omitempty"`
+	// Loss is the percentage of dropped outgoing packets, eg. "5%".
+	Loss string `json:"loss,omitempty"`
+	// Rate limits the outgoing bandwidth, eg. "1mbit".
+	Rate string `json:"rate,omitempty"`
+}
+
+var rateRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(bit|kbit|mbit|gbit|tbit|bps|kbps|mbps|gbps|tbps)$`)
+
+// IsZero reports whether no shaping is configured.
+func (conf Shaping) IsZero() bool {
+	return conf == Shaping{}
+}
+
+// Validate checks basic rules for Shaping's fields
+func (conf Shaping) Validate() error {
+	if conf.IsZero() {
+		return errors.New("at least one of delay, jitter, loss and rate is required")
+	}
+	for name, value := range map[string]string{"delay": conf.Delay, "jitter": conf.Jitter} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return fmt.Errorf("invalid %s %q, expected a duration such as 100ms", name, value)
+		}
+	}
+	if conf.Jitter != "" && conf.Delay == "" {
+		return errors.New("jitter requires a delay")
+	}
+	if conf.Loss != "" {
+		loss, err := strconv.ParseFloat(strings.TrimSuffix(conf.Loss, "%"), 64)
+		if err != nil || !strings.HasSuffix(conf.Loss, "%") || loss < 0 || loss > 100 {
+			return fmt.Errorf("invalid loss %q, expected a percentage such as 5%%", conf.Loss)
+		}
+	}
+	if conf.Rate != "" && !rateRegexp.MatchString(conf.Rate) {
+		return fmt.Errorf("invalid rate %q, expected a rate such as 1mbit", conf.Rate)
+	}
+	return nil
+}
+
+// NetemArgs returns the arguments of the netem queueing discipline
+// implementing the shaping.
+func (conf Shaping) NetemArgs() []string {
+	args := []string{"netem"}
+	if conf.Delay != "" {
+		args = append(args, "delay", conf.Delay)
+		if conf.Jitter != "" {
+			args = append(args, conf.Jitter)
+		}
+	}
+	if conf.Loss != "" {
+		args = append(args, "loss", conf.Loss)
+	}
+	if conf.Rate != "" {
+		args = append(args, "rate", conf.Rate)
+	}
+	return args
+}
+
+// Fault is a network fault applied to some machines. The faults of a
+// configuration are applied and reverted together with 'bootloose net apply'
+// and 'bootloose net revert'.
+type Fault struct {
+	// Machines are the hostnames of the machines affected by the fault.
+	Machines []string `json:"machines"`
+	// Partition disconnects the machines from their networks.
+	Partition bool `json:"partition,omitempty"`
+	// Network restricts the partition to a single network.
+	Network string `json:"network,omitempty"`
+	// Shape applies traffic control to the machines.
+	Shape *Shaping `json:"shape,omitempty"`
+}
+
+// validate checks basic rules for Fault's fields
+func (conf Fault) validate(hostnames map[string]bool) error {
+	if len(conf.Machines) == 0 {
+		return errors.New("machines is required")
+	}
+	for _, machine := range conf.Machines {
+		if !hostnames[machine] {
+			return fmt.Errorf("%s: invalid machine hostname", machine)
+		}
+	}
+	if !conf.Partition && conf.Shape == nil {
+		return errors.New("one of partition and shape is required")
+	}
+	if conf.Network != "" && !conf.Partition {
+		return errors.New("network can only be used with partition")
+	}
+	if conf.Shape != nil {
+		if err := conf.Shape.Validate(); err != nil {
+			return fmt.Errorf("shape: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShapingNetemArgs(t *testing.T) {
+	shaping := Shaping{Delay: "100ms", Jitter: "10ms", Loss: "5%", Rate: "1mbit"}
+	require.NoError(t, shaping.Validate())
+	assert.Equal(t, []string{"netem", "delay", "100ms", "10ms", "loss", "5%", "rate", "1mbit"}, shaping.NetemArgs())
+	assert.Equal(t, []string{"netem", "rate", "512kbit"}, Shaping{Rate: "512kbit"}.NetemArgs())
+}
+
+func TestShapingValidate(t *testing.T) {
+	for shaping, msg := range map[Shaping]string{
+		{}:                             "at least one of delay, jitter, loss and rate is required",
+		{Delay: "100"}:                 `invalid delay "100"`,
+		{Jitter: "10ms"}:               "jitter requires a delay",
+		{Loss: "5"}:                    `invalid loss "5"`,
+		{Loss: "120%"}:                 `invalid loss "120%"`,
+		{Rate: "fast"}:                 `invalid rate "fast"`,
+		{Delay: "1s", Jitter: "-10ms"}: `invalid jitter "-10ms"`,
+	} {
+		assert.ErrorContains(t, shaping.Validate(), msg)
+	}
+}
+
+func TestConfigValidateFaults(t *testing.T) {
+	conf, err := NewConfigFromYAML([]byte(`cluster:
+  name: cluster
+machines:
+- count: 3
+  spec:
+    name: node%d
+faults:
+- machines: [node0]
+  partition: true
+- machines: [node1, node2]
+  shape:
+    delay: 100ms
+    loss: 5%
+`))
+	require.NoError(t, err)
+	require.NoError(t, conf.Validate())
+
+	conf.Faults[0].Machines = []string{"node3"}
+	assert.ErrorContains(t, conf.Validate(), "faults[0]: node3: invalid machine hostname")
+
+	conf.Faults[0] = Fault{Machines: []string{"node0"}}
+	assert.ErrorContains(t, conf.Validate(), "faults[0]: one of partition and shape is required")
+
+	conf.Faults[0] = Fault{Machines: []string{"node0"}, Network: "net1", Shape: &Shaping{Rate: "1mbit"}}
+	assert.ErrorContains(t, conf.Validate(), "faults[0]: network can only be used with partition")
+
+	conf.Faults[0] = Fault{Machines: []string{"node0"}, Shape: &Shaping{Rate: "1"}}
+	assert.ErrorContains(t, conf.Validate(), `faults[0]: shape: invalid rate "1"`)
+}
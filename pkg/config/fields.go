@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// jsonName returns the name encoding/json uses for the struct field, or "" if
+// the field is not serialized.
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// omitted reports whether encoding/json leaves the field value out, that is
+// when the field is tagged omitempty and v is empty.
+func omitted(field reflect.StructField, v reflect.Value) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if !strings.Contains(","+opts+",", ",omitempty,") {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// jsonField looks up the struct field serialized under name. Like
+// encoding/json, an exact match is preferred over a case-insensitive one.
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldName := jsonName(field)
+		if fieldName == "" {
+			continue
+		}
+		if fieldName == name {
+			return field, true
+		}
+		if folded == nil && strings.EqualFold(fieldName, name) {
+			folded = &field
+		}
+	}
+	if folded != nil {
+		return *folded, true
+	}
+	return reflect.StructField{}, false
+}
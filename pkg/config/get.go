@@ -7,41 +7,510 @@ package config
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+)
+
+// GetValueFromConfig returns specific value from object given a string path.
+//
+// Keys are the json names of the configuration fields, eg.
+// machines[0].spec.portMappings[0].hostPort. Besides plain keys and indexes,
+// the path supports a subset of JSONPath:
+//
+//	machines[*].spec.image               wildcards, on lists, maps and objects
+//	machines[-1]                         negative indexes, from the end
+//	machines[1:3]                        slices, with optional bounds
+//	machines[?(@.count > 1)]             filters, comparing with ==, !=, <, <=,
+//	machines[?(@.spec.name == "w%d")]    >, >= or matching a regular
+//	machines[?(@.spec.image =~ "^q")]    expression with =~
+//	machines[?(@.spec.privileged)]       or testing for a non-zero value
+//
+// A leading "$" or "." is accepted. When the path contains wildcards, slices
+// or filters, the matching values are returned as a []interface{}.
+func GetValueFromConfig(stringPath string, object interface{}) (interface{}, error) {
+	segments, err := parsePath(stringPath)
+	if err != nil {
+		return nil, err
+	}
+	definite := isDefinite(segments)
+	values, err := evaluatePath(reflect.ValueOf(object), segments, definite)
+	if err != nil {
+		return nil, err
+	}
+	if definite {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%s: no value found", stringPath)
+		}
+		return values[0].Interface(), nil
+	}
+	res := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		res = append(res, v.Interface())
+	}
+	return res, nil
+}
+
+type segmentKind int
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+const (
+	segmentKey segmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentSlice
+	segmentFilter
 )
 
-func pathSplit(r rune) bool {
-	return r == '.' || r == '[' || r == ']' || r == '"'
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+	// slice bounds, nil when omitted.
+	start, end *int
+	filter     *filter
 }
 
-// GetValueFromConfig returns specific value from object given a string path
-func GetValueFromConfig(stringPath string, object interface{}) (interface{}, error) {
-	keyPath := strings.FieldsFunc(stringPath, pathSplit)
-	v := reflect.ValueOf(object)
-	caser := cases.Title(language.English)
-	for _, key := range keyPath {
-		keyTitle := caser.String(key)
-		for v.Kind() == reflect.Pointer {
-			v = v.Elem()
-		}
-		if v.Kind() == reflect.Struct {
-			v = v.FieldByName(keyTitle)
-			if !v.IsValid() {
-				return nil, fmt.Errorf("%v key does not exist", keyTitle)
-			}
-		} else if v.Kind() == reflect.Slice {
-			index, errConv := strconv.Atoi(keyTitle)
-			if errConv != nil {
-				return nil, fmt.Errorf("%v is not an index", key)
-			}
-			v = v.Index(index)
-		} else {
-			return nil, fmt.Errorf("%v is neither a slice or a struct", v)
-		}
-	}
-	return v.Interface(), nil
+type filter struct {
+	path     []segment
+	operator string
+	operand  string
+	quoted   bool
+}
+
+var filterOperators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func isDefinite(segments []segment) bool {
+	for _, s := range segments {
+		if s.kind != segmentKey && s.kind != segmentIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePath tokenizes a path expression into segments.
+func parsePath(path string) ([]segment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+	var segments []segment
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			i++
+			if i < len(p) && p[i] == '.' {
+				return nil, fmt.Errorf("%s: recursive descent is not supported", path)
+			}
+			if i < len(p) && p[i] == '*' {
+				segments = append(segments, segment{kind: segmentWildcard})
+				i++
+				continue
+			}
+			name := readName(p[i:])
+			if name == "" {
+				if i == len(p) && len(segments) == 0 {
+					// A lone "." or "$." selects the whole object.
+					continue
+				}
+				return nil, fmt.Errorf("%s: missing key at position %d", path, i)
+			}
+			segments = append(segments, keySegment(name))
+			i += len(name)
+		case '[':
+			end, err := closingBracket(p, i)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			s, err := parseBracket(strings.TrimSpace(p[i+1 : end]))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			segments = append(segments, s)
+			i = end + 1
+		case '"':
+			// Quoted keys are accepted for compatibility: machines[0]."spec"
+			end := strings.IndexByte(p[i+1:], '"')
+			if end == -1 {
+				return nil, fmt.Errorf("%s: unterminated quote", path)
+			}
+			segments = append(segments, segment{kind: segmentKey, key: p[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			if len(segments) != 0 {
+				return nil, fmt.Errorf("%s: unexpected %q at position %d", path, p[i], i)
+			}
+			name := readName(p[i:])
+			segments = append(segments, keySegment(name))
+			i += len(name)
+		}
+	}
+	return segments, nil
+}
+
+func readName(s string) string {
+	end := strings.IndexAny(s, ".[")
+	if end == -1 {
+		return s
+	}
+	return s[:end]
+}
+
+// keySegment turns a dotted key into a segment. Numeric keys are indexes, so
+// that machines.0.spec keeps working.
+func keySegment(name string) segment {
+	if index, err := strconv.Atoi(name); err == nil {
+		return segment{kind: segmentIndex, index: index, key: name}
+	}
+	return segment{kind: segmentKey, key: name}
+}
+
+// closingBracket returns the position of the bracket closing the one opened at
+// start, skipping over quoted strings and parentheses.
+func closingBracket(p string, start int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := start + 1; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']' && depth > 0:
+			depth--
+		case c == ']':
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("missing ] for [ at position %d", start)
+}
+
+func parseBracket(s string) (segment, error) {
+	switch {
+	case s == "*":
+		return segment{kind: segmentWildcard}, nil
+	case strings.HasPrefix(s, "?"):
+		f, err := parseFilter(s)
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{kind: segmentFilter, filter: f}, nil
+	case isQuoted(s):
+		return segment{kind: segmentKey, key: s[1 : len(s)-1]}, nil
+	case strings.Contains(s, ":"):
+		from, to, _ := strings.Cut(s, ":")
+		start, err := parseBound(from)
+		if err != nil {
+			return segment{}, err
+		}
+		end, err := parseBound(to)
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{kind: segmentSlice, start: start, end: end}, nil
+	case s == "":
+		return segment{}, fmt.Errorf("empty brackets")
+	default:
+		return keySegment(s), nil
+	}
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
+
+func parseBound(s string) (*int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("%v is not an index", s)
+	}
+	return &i, nil
+}
+
+// parseFilter parses "?(@.path op operand)" or "?(@.path)".
+func parseFilter(s string) (*filter, error) {
+	expr := strings.TrimSpace(strings.TrimPrefix(s, "?"))
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return nil, fmt.Errorf("filter %q must be of the form ?(@.key op value)", s)
+	}
+	expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	if !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("filter %q must start with @", s)
+	}
+	expr = expr[1:]
+
+	f := &filter{}
+	left := expr
+	if pos, op := findOperator(expr); pos != -1 {
+		left = expr[:pos]
+		f.operator = op
+		f.operand = strings.TrimSpace(expr[pos+len(op):])
+		if isQuoted(f.operand) {
+			f.operand = f.operand[1 : len(f.operand)-1]
+			f.quoted = true
+		} else if f.operand == "" {
+			return nil, fmt.Errorf("filter %q is missing a value after %s", s, op)
+		}
+	}
+	path, err := parsePath(strings.TrimSpace(left))
+	if err != nil {
+		return nil, err
+	}
+	if !isDefinite(path) {
+		return nil, fmt.Errorf("filter %q can only use keys and indexes", s)
+	}
+	f.path = path
+	return f, nil
+}
+
+// findOperator returns the position of the first comparison operator outside
+// of quotes.
+func findOperator(expr string) (int, string) {
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		for _, op := range filterOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				return i, op
+			}
+		}
+	}
+	return -1, ""
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// evaluatePath applies the segments to v. When definite is true, missing
+// values are reported as errors rather than skipped.
+func evaluatePath(v reflect.Value, segments []segment, definite bool) ([]reflect.Value, error) {
+	current := []reflect.Value{v}
+	for _, s := range segments {
+		var next []reflect.Value
+		for _, c := range current {
+			c = indirect(c)
+			if !c.IsValid() {
+				if definite {
+					return nil, fmt.Errorf("%v is not set", describe(s))
+				}
+				continue
+			}
+			values, err := applySegment(c, s, definite)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, values...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func describe(s segment) string {
+	if s.kind == segmentIndex && s.key == "" {
+		return strconv.Itoa(s.index)
+	}
+	return s.key
+}
+
+func applySegment(v reflect.Value, s segment, definite bool) ([]reflect.Value, error) {
+	switch s.kind {
+	case segmentKey:
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(v.Type(), s.key)
+			if !ok {
+				return nil, fmt.Errorf("%v key does not exist", s.key)
+			}
+			return []reflect.Value{v.FieldByIndex(field.Index)}, nil
+		case reflect.Map:
+			elem := v.MapIndex(reflect.ValueOf(s.key).Convert(v.Type().Key()))
+			if !elem.IsValid() {
+				if definite {
+					return nil, fmt.Errorf("%v key does not exist", s.key)
+				}
+				return nil, nil
+			}
+			return []reflect.Value{elem}, nil
+		case reflect.Slice, reflect.Array:
+			return nil, fmt.Errorf("%v is not an index", s.key)
+		}
+	case segmentIndex:
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			index := s.index
+			if index < 0 {
+				index += v.Len()
+			}
+			if index < 0 || index >= v.Len() {
+				if definite {
+					return nil, fmt.Errorf("index %d is out of range", s.index)
+				}
+				return nil, nil
+			}
+			return []reflect.Value{v.Index(index)}, nil
+		case reflect.Struct, reflect.Map:
+			return applySegment(v, segment{kind: segmentKey, key: describe(s)}, definite)
+		}
+	case segmentWildcard:
+		return children(v)
+	case segmentSlice:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("%v is not a slice", v.Type())
+		}
+		start, end := sliceBounds(s, v.Len())
+		var res []reflect.Value
+		for i := start; i < end; i++ {
+			res = append(res, v.Index(i))
+		}
+		return res, nil
+	case segmentFilter:
+		elems, err := children(v)
+		if err != nil {
+			return nil, err
+		}
+		var res []reflect.Value
+		for _, elem := range elems {
+			ok, err := s.filter.matches(elem)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				res = append(res, elem)
+			}
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("%v is neither a slice, a map or a struct", v.Type())
+}
+
+// children returns the elements of a slice, the values of a map sorted by key
+// or the serialized fields of a struct.
+func children(v reflect.Value) ([]reflect.Value, error) {
+	var res []reflect.Value
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			res = append(res, v.Index(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			res = append(res, v.MapIndex(k))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if jsonName(field) != "" && !omitted(field, v.Field(i)) {
+				res = append(res, v.Field(i))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%v is neither a slice, a map or a struct", v.Type())
+	}
+	return res, nil
+}
+
+func sliceBounds(s segment, length int) (int, int) {
+	bound := func(b *int, def int) int {
+		if b == nil {
+			return def
+		}
+		i := *b
+		if i < 0 {
+			i += length
+		}
+		return max(0, min(i, length))
+	}
+	start, end := bound(s.start, 0), bound(s.end, length)
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+func (f *filter) matches(v reflect.Value) (bool, error) {
+	values, err := evaluatePath(v, f.path, false)
+	if err != nil {
+		return false, err
+	}
+	if len(values) == 0 {
+		return false, nil
+	}
+	value := indirect(values[0])
+	if f.operator == "" {
+		return value.IsValid() && !value.IsZero(), nil
+	}
+
+	var actual string
+	if value.IsValid() {
+		actual = fmt.Sprint(value.Interface())
+	}
+
+	if f.operator == "=~" {
+		re, err := regexp.Compile(f.operand)
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression in filter: %w", err)
+		}
+		return re.MatchString(actual), nil
+	}
+
+	cmp := strings.Compare(actual, f.operand)
+	if !f.quoted {
+		a, errA := strconv.ParseFloat(actual, 64)
+		b, errB := strconv.ParseFloat(f.operand, 64)
+		if errA == nil && errB == nil {
+			switch {
+			case a < b:
+				cmp = -1
+			case a > b:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+
+	switch f.operator {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetValueFromConfig(t *testing.T) {
@@ -66,3 +67,87 @@ func TestGetValueFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestGetValueFromConfigQueries(t *testing.T) {
+	config := Config{
+		Cluster: Cluster{Name: "clustername", PrivateKey: "privatekey"},
+		Machines: []MachineReplicas{
+			{
+				Count: 3,
+				Spec: &Machine{
+					Name:         "controller%d",
+					Image:        "quay.io/k0sproject/bootloose-debian13",
+					PortMappings: []PortMapping{{ContainerPort: 22, HostPort: 2222}, {ContainerPort: 6443}},
+				},
+			},
+			{
+				Count: 2,
+				Spec: &Machine{
+					Name:       "worker%d",
+					Image:      "quay.io/k0sproject/bootloose-ubuntu24.04",
+					Privileged: true,
+					ExtraArgs:  []string{"--cpus=2"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		stringPath string
+		expected   interface{}
+	}{
+		{"json tag key", "cluster.privateKey", "privatekey"},
+		{"camel case key", "machines[0].spec.portMappings[0].hostPort", uint16(2222)},
+		{"extra args", "machines[1].spec.extraArgs", []string{"--cpus=2"}},
+		{"leading dollar", "$.cluster.name", "clustername"},
+		{"leading dot", ".cluster.name", "clustername"},
+		{"dotted index", "machines.1.count", 2},
+		{"negative index", "machines[-1].spec.name", "worker%d"},
+		{"quoted key", `machines[0]["spec"].name`, "controller%d"},
+		{"wildcard", "machines[*].spec.image", []interface{}{
+			"quay.io/k0sproject/bootloose-debian13",
+			"quay.io/k0sproject/bootloose-ubuntu24.04",
+		}},
+		{"nested wildcard", "machines[*].spec.portMappings[*].containerPort", []interface{}{uint16(22), uint16(6443)}},
+		{"dot wildcard", "cluster.*", []interface{}{"clustername", "privatekey"}},
+		{"slice", "machines[1:].count", []interface{}{2}},
+		{"slice with negative bound", "machines[:-1].count", []interface{}{3}},
+		{"filter equal", `machines[?(@.spec.name == "worker%d")].count`, []interface{}{2}},
+		{"filter numeric", "machines[?(@.count > 2)].spec.name", []interface{}{"controller%d"}},
+		{"filter regexp", `machines[?(@.spec.image =~ "ubuntu")].spec.name`, []interface{}{"worker%d"}},
+		{"filter existence", "machines[?(@.spec.privileged)].spec.name", []interface{}{"worker%d"}},
+		{"filter no match", "machines[?(@.count > 5)]", []interface{}{}},
+		{"missing index in wildcard", "machines[*].spec.portMappings[1].containerPort", []interface{}{uint16(6443)}},
+	}
+
+	for _, utest := range tests {
+		t.Run(utest.name, func(t *testing.T) {
+			res, err := GetValueFromConfig(utest.stringPath, config)
+			require.NoError(t, err)
+			assert.Equal(t, utest.expected, res)
+		})
+	}
+}
+
+func TestGetValueFromConfigErrors(t *testing.T) {
+	config := DefaultConfig()
+
+	for path, msg := range map[string]string{
+		"cluster.nope":                         "nope key does not exist",
+		"machines[3]":                          "index 3 is out of range",
+		"machines.spec":                        "spec is not an index",
+		"cluster.name.first":                   "is neither a slice, a map or a struct",
+		"machines[0":                           "missing ] for [",
+		"machines..spec":                       "recursive descent is not supported",
+		"machines[?(count > 1)]":               "must start with @",
+		`machines[?(@.spec.name =~ "(")]`:      "invalid regular expression",
+		"machines[?(@.spec.portMappings[*])]":  "can only use keys and indexes",
+		"machines[*].spec.portMappings[*].foo": "foo key does not exist",
+	} {
+		t.Run(path, func(t *testing.T) {
+			_, err := GetValueFromConfig(path, config)
+			assert.ErrorContains(t, err, msg)
+		})
+	}
+}
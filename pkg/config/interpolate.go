@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LoadOption customizes how a configuration is loaded.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	interpolate bool
+	strict      bool
+	lookupEnv   func(string) (string, bool)
+	baseDir     string
+}
+
+func newLoadOptions(opts []LoadOption) *loadOptions {
+	o := &loadOptions{
+		interpolate: true,
+		lookupEnv:   os.LookupEnv,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithStrictInterpolation makes loading fail when the configuration references
+// an undefined variable without providing a default value.
+func WithStrictInterpolation() LoadOption {
+	return func(o *loadOptions) {
+		o.strict = true
+	}
+}
+
+// WithoutInterpolation loads the configuration verbatim. This is what should
+// be used when the configuration is going to be written back to disk.
+func WithoutInterpolation() LoadOption {
+	return func(o *loadOptions) {
+		o.interpolate = false
+	}
+}
+
+// WithLookupEnv replaces os.LookupEnv as the source of variables.
+func WithLookupEnv(lookupEnv func(string) (string, bool)) LoadOption {
+	return func(o *loadOptions) {
+		o.lookupEnv = lookupEnv
+	}
+}
+
+// WithBaseDir sets the directory relative ${file:path} references are resolved
+// against. Defaults to the current working directory.
+func WithBaseDir(dir string) LoadOption {
+	return func(o *loadOptions) {
+		o.baseDir = dir
+	}
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// interpolateValue replaces references in the string values of a decoded YAML
+// document. The following forms are supported:
+//
+//	${VAR}           value of the environment variable VAR
+//	${VAR:-default}  value of VAR, or default if VAR is unset or empty
+//	${file:path}     content of the file at path, minus a trailing newline
+//	$$               a literal $
+//
+// t is the type the value is going to be decoded into. It's used to convert
+// interpolated strings into numbers and booleans where the configuration
+// expects them.
+func (o *loadOptions) interpolateValue(value interface{}, t reflect.Type, path string) (interface{}, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		keys := make([]string, 0, len(v))
+		byName := make(map[string]interface{}, len(v))
+		for k := range v {
+			name := fmt.Sprint(k)
+			keys = append(keys, name)
+			byName[name] = k
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			var elemType reflect.Type
+			if t != nil {
+				switch t.Kind() {
+				case reflect.Struct:
+					if field, ok := jsonField(t, name); ok {
+						elemType = field.Type
+					}
+				case reflect.Map:
+					elemType = t.Elem()
+				}
+			}
+			k := byName[name]
+			res, err := o.interpolateValue(v[k], elemType, joinPath(path, name))
+			if err != nil {
+				return nil, err
+			}
+			v[k] = res
+		}
+		return v, nil
+	case []interface{}:
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+		for i := range v {
+			res, err := o.interpolateValue(v[i], elemType, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = res
+		}
+		return v, nil
+	case string:
+		if !strings.Contains(v, "$") {
+			return v, nil
+		}
+		expanded, err := o.expand(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if t == nil {
+			return expanded, nil
+		}
+		converted, err := convertScalar(expanded, t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return converted, nil
+	}
+	return value, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// expand performs the substitutions in a single string.
+func (o *loadOptions) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end == -1 {
+				return "", fmt.Errorf("unterminated reference in %q", s)
+			}
+			value, err := o.resolve(s[i+2 : i+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// resolve returns the value of a single reference, without the enclosing ${}.
+func (o *loadOptions) resolve(ref string) (string, error) {
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		if path == "" {
+			return "", fmt.Errorf("empty file path in ${%s}", ref)
+		}
+		if !filepath.IsAbs(path) && o.baseDir != "" {
+			path = filepath.Join(o.baseDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file referenced by ${%s}: %w", ref, err)
+		}
+		return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+	}
+
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if !variableName.MatchString(name) {
+		return "", fmt.Errorf("invalid variable name in ${%s}", ref)
+	}
+	value, ok := o.lookupEnv(name)
+	if hasDefault && value == "" {
+		return def, nil
+	}
+	if !ok {
+		if o.strict {
+			return "", fmt.Errorf("variable %s is not set", name)
+		}
+		log.Warnf("Variable %s is not set, defaulting to a blank string", name)
+	}
+	return value, nil
+}
+
+// convertScalar converts an interpolated string into the kind of value the
+// configuration field expects. A blank value leaves the field unset.
+func convertScalar(s string, t reflect.Type) (interface{}, error) {
+	var (
+		v   interface{}
+		err error
+	)
+	if s == "" && t.Kind() != reflect.String {
+		return nil, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		v, err = strconv.ParseBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(s, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err = strconv.ParseUint(s, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(s, t.Bits())
+	default:
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot use %q as %s", s, t.Kind())
+	}
+	return v, nil
+}
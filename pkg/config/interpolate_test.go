@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupEnvFrom(env map[string]string) LoadOption {
+	return WithLookupEnv(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+}
+
+const interpolatedConfig = `cluster:
+  name: ${CLUSTER_NAME}
+  privateKey: ${KEY:-cluster-key}
+machines:
+- count: ${COUNT:-1}
+  spec:
+    image: quay.io/k0sproject/bootloose-${IMAGE}
+    name: node%d
+    privileged: ${PRIVILEGED:-false}
+    cmd: echo $$HOME
+    portMappings:
+    - containerPort: 22
+      hostPort: ${SSH_PORT}
+`
+
+func TestNewConfigFromYAMLInterpolation(t *testing.T) {
+	env := lookupEnvFrom(map[string]string{
+		"CLUSTER_NAME": "ci-1234",
+		"COUNT":        "3",
+		"IMAGE":        "debian13:v1.10",
+		"PRIVILEGED":   "true",
+		"SSH_PORT":     "2300",
+	})
+
+	conf, err := NewConfigFromYAML([]byte(interpolatedConfig), env)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ci-1234", conf.Cluster.Name)
+	assert.Equal(t, "cluster-key", conf.Cluster.PrivateKey)
+	require.Len(t, conf.Machines, 1)
+	assert.Equal(t, 3, conf.Machines[0].Count)
+	spec := conf.Machines[0].Spec
+	assert.Equal(t, "quay.io/k0sproject/bootloose-debian13:v1.10", spec.Image)
+	assert.True(t, spec.Privileged)
+	assert.Equal(t, "echo $HOME", spec.Cmd)
+	assert.Equal(t, uint16(2300), spec.PortMappings[0].HostPort)
+}
+
+func TestNewConfigFromYAMLStrictInterpolation(t *testing.T) {
+	env := lookupEnvFrom(map[string]string{"CLUSTER_NAME": "ci", "IMAGE": "debian13"})
+
+	_, err := NewConfigFromYAML([]byte(interpolatedConfig), env, WithStrictInterpolation())
+	assert.ErrorContains(t, err, "machines[0].spec.portMappings[0].hostPort: variable SSH_PORT is not set")
+
+	conf, err := NewConfigFromYAML([]byte(interpolatedConfig), env)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0), conf.Machines[0].Spec.PortMappings[0].HostPort)
+}
+
+func TestNewConfigFromYAMLInvalidValue(t *testing.T) {
+	env := lookupEnvFrom(map[string]string{"CLUSTER_NAME": "ci", "IMAGE": "debian13", "SSH_PORT": "ssh"})
+
+	_, err := NewConfigFromYAML([]byte(interpolatedConfig), env)
+	assert.ErrorContains(t, err, `machines[0].spec.portMappings[0].hostPort: cannot use "ssh" as uint16`)
+}
+
+func TestNewConfigFromYAMLWithoutInterpolation(t *testing.T) {
+	conf, err := NewConfigFromYAML([]byte("cluster:\n  name: ${CLUSTER_NAME}\n"), WithoutInterpolation())
+	require.NoError(t, err)
+	assert.Equal(t, "${CLUSTER_NAME}", conf.Cluster.Name)
+}
+
+func TestNewConfigFromFileInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key-path"), []byte("/secrets/key\n"), 0o600))
+	path := filepath.Join(dir, "bootloose.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("cluster:\n  name: test\n  privateKey: ${file:key-path}\n"), 0o600))
+
+	conf, err := NewConfigFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/secrets/key", conf.Cluster.PrivateKey)
+
+	require.NoError(t, os.WriteFile(path, []byte("cluster:\n  privateKey: ${file:missing}\n"), 0o600))
+	_, err = NewConfigFromFile(path)
+	assert.ErrorContains(t, err, "cluster.privateKey: failed to read file referenced by ${file:missing}")
+}
+
+func TestExpand(t *testing.T) {
+	o := newLoadOptions([]LoadOption{lookupEnvFrom(map[string]string{"A": "a", "EMPTY": ""})})
+
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{"${A}", "a"},
+		{"x${A}y${A}z", "xayaz"},
+		{"${EMPTY:-default}", "default"},
+		{"${UNSET:-}", ""},
+		{"${UNSET:-with space}", "with space"},
+		{"$$", "$"},
+		{"$${A}", "${A}"},
+		{"$A", "$A"},
+		{"trailing $", "trailing $"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			out, err := o.expand(tc.in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+
+	_, err := o.expand("${A")
+	assert.ErrorContains(t, err, "unterminated reference")
+	_, err = o.expand("${not-a-var}")
+	assert.ErrorContains(t, err, "invalid variable name")
+}
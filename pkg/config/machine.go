@@ -5,10 +5,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"slices"
+	"strconv"
 	"strings"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // Volume is a volume that can be attached to a Machine.
@@ -32,17 +34,49 @@ type PortMapping struct {
 	// Protocol is the layer 4 protocol for this mapping. One of "tcp" or "udp".
 	// Defaults to "tcp".
 	Protocol string `json:"protocol,omitempty"`
-	// Address is the host address to bind to. Defaults to "0.0.0.0".
+	// Address is the host address to bind to, an IPv4 or IPv6 address.
+	// Defaults to "0.0.0.0".
 	Address string `json:"address,omitempty"`
 	// HostPort is the base host port to map the containers ports to. As we
 	// configure a number of machine replicas, each machine will use HostPort+i
 	// where i is between 0 and N-1, N being the number of machine replicas. If 0,
 	// a local port will be automatically allocated.
 	HostPort uint16 `json:"hostPort,omitempty"`
+	// HostPortRange is a range of host ports, eg. "2222-2299", each machine
+	// using the first port of the range available on the host. It can't be used
+	// along with HostPort.
+	HostPortRange string `json:"hostPortRange,omitempty"`
 	// ContainerPort is the container port to map.
 	ContainerPort uint16 `json:"containerPort"`
 }
 
+// HostAddress returns the host address in a form that can be followed by a
+// port, with IPv6 addresses enclosed in brackets.
+func (p PortMapping) HostAddress() string {
+	address := strings.TrimSuffix(strings.TrimPrefix(p.Address, "["), "]")
+	if strings.Contains(address, ":") {
+		return "[" + address + "]"
+	}
+	return address
+}
+
+// HostPorts returns the first and last ports of HostPortRange.
+func (p PortMapping) HostPorts() (uint16, uint16, error) {
+	first, last, ok := strings.Cut(p.HostPortRange, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("host port range %q is not valid, it should be FIRST-LAST", p.HostPortRange)
+	}
+	start, err := strconv.ParseUint(strings.TrimSpace(first), 10, 16)
+	if err != nil || start == 0 {
+		return 0, 0, fmt.Errorf("host port range %q is not valid, invalid first port", p.HostPortRange)
+	}
+	end, err := strconv.ParseUint(strings.TrimSpace(last), 10, 16)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("host port range %q is not valid, invalid last port", p.HostPortRange)
+	}
+	return uint16(start), uint16(end), nil
+}
+
 // Machine is the machine configuration.
 type Machine struct {
 	// Name is the machine name.
@@ -62,11 +96,17 @@ type Machine struct {
 	// Volumes is the list of volumes attached to this machine.
 	Volumes []Volume `json:"volumes,omitempty"`
 	// Networks is the list of user-defined docker networks this machine is
-	// attached to. These networks have to be created manually before creating the
-	// containers via "docker network create mynetwork"
+	// attached to. These networks are either declared in the cluster networks or
+	// have to be created manually before creating the containers via
+	// "docker network create mynetwork"
 	Networks []string `json:"networks,omitempty"`
+	// Addresses assigns static IP addresses to the machines on some of their
+	// networks. Other networks use addresses allocated by docker.
+	Addresses []NetworkAddress `json:"addresses,omitempty"`
 	// PortMappings is the list of ports to expose to the host.
 	PortMappings []PortMapping `json:"portMappings,omitempty"`
+	// Env is the set of environment variables defined in the container.
+	Env map[string]string `json:"env,omitempty"`
 	// ExtraArgs is the list of extra arguments passed to docker
 	ExtraArgs []string `json:"extraArgs,omitempty"`
 	// Cmd is a cmd which will be run in the container.
@@ -74,14 +114,66 @@ type Machine struct {
 	// PublicKey is the name of the public key to upload onto the machine for root
 	// SSH access.
 	PublicKey string `json:"publicKey,omitempty"`
+	// PublicKeys are the names of more public keys to upload onto the machine
+	// for root SSH access, along with PublicKey.
+	PublicKeys []string `json:"publicKeys,omitempty"`
+	// Labels are arbitrary key/value pairs describing the machines, eg. their
+	// role. The inventory command groups machines by label.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Users are the user accounts created on the machine. They take precedence
+	// over the cluster users of the same name.
+	Users []User `json:"users,omitempty"`
 }
 
 // validate checks basic rules for Machine's fields
 func (conf Machine) validate() error {
-	validName := strings.Contains(conf.Name, "%d")
-	if !validName {
-		log.Warnf("Machine conf validation: machine name %v is not valid, it should contains %%d", conf.Name)
-		return fmt.Errorf("Machine configuration not valid")
+	if !strings.Contains(conf.Name, "%d") {
+		return fmt.Errorf("machine name %q is not valid, it should contain %%d", conf.Name)
+	}
+	for i, mapping := range conf.PortMappings {
+		if mapping.HostPortRange != "" {
+			if mapping.HostPort != 0 {
+				return fmt.Errorf("portMappings[%d]: hostPort and hostPortRange are mutually exclusive", i)
+			}
+			if _, _, err := mapping.HostPorts(); err != nil {
+				return fmt.Errorf("portMappings[%d]: %w", i, err)
+			}
+		}
+		if mapping.Address == "" {
+			continue
+		}
+		if net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(mapping.Address, "["), "]")) == nil {
+			return fmt.Errorf("portMappings[%d]: address %q is not an IP address", i, mapping.Address)
+		}
+	}
+	for i, name := range conf.PublicKeys {
+		if name == "" {
+			return fmt.Errorf("publicKeys[%d]: key name cannot be empty", i)
+		}
+	}
+	for key := range conf.Labels {
+		if key == "" {
+			return errors.New("labels: label keys cannot be empty")
+		}
+	}
+	if err := validateUsers(conf.Users); err != nil {
+		return err
+	}
+	networks := map[string]bool{}
+	for i, address := range conf.Addresses {
+		if err := address.validate(); err != nil {
+			return fmt.Errorf("addresses[%d]: %w", i, err)
+		}
+		if !slices.Contains(conf.Networks, address.Network) {
+			return fmt.Errorf("addresses[%d]: %s is not one of the machine networks", i, address.Network)
+		}
+		if address.Network == "bridge" {
+			return fmt.Errorf("addresses[%d]: static addresses cannot be used on the default bridge network", i)
+		}
+		if networks[address.Network] {
+			return fmt.Errorf("addresses[%d]: network %s has more than one address", i, address.Network)
+		}
+		networks[address.Network] = true
 	}
 	return nil
 }
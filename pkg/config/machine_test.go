@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortMappingHostPorts(t *testing.T) {
+	first, last, err := PortMapping{HostPortRange: "2222-2299"}.HostPorts()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2222), first)
+	assert.Equal(t, uint16(2299), last)
+
+	for _, hostPortRange := range []string{"2222", "0-10", "2299-2222", "2222-70000", "a-b"} {
+		_, _, err := PortMapping{HostPortRange: hostPortRange}.HostPorts()
+		assert.Error(t, err, hostPortRange)
+	}
+
+	machine := Machine{
+		Name:         "node%d",
+		PortMappings: []PortMapping{{ContainerPort: 22, HostPort: 2222, HostPortRange: "2222-2299"}},
+	}
+	assert.ErrorContains(t, machine.validate(), "hostPort and hostPortRange are mutually exclusive")
+}
+
+func TestValidateUsers(t *testing.T) {
+	assert.NoError(t, validateUsers([]User{{Name: "k0s", UID: 1000, Groups: []string{"wheel"}, Shell: "/bin/bash", Sudo: true}}))
+	for _, users := range [][]User{
+		{{Name: "root"}},
+		{{Name: "Bad Name"}},
+		{{Name: "k0s", Groups: []string{"a;b"}}},
+		{{Name: "k0s", Shell: "bash"}},
+		{{Name: "k0s", AuthorizedKeys: []string{""}}},
+		{{Name: "k0s"}, {Name: "k0s"}},
+	} {
+		assert.Error(t, validateUsers(users), "%v", users)
+	}
+}
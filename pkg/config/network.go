@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Network is a docker network created and removed along with the cluster.
+type Network struct {
+	// Name is the name of the docker network. Machines are attached to it by
+	// listing this name in their networks.
+	Name string `json:"name"`
+	// Driver is the network driver. Defaults to "bridge".
+	Driver string `json:"driver,omitempty"`
+	// Subnet is the IPv4 subnet of the network in CIDR notation, eg.
+	// "172.30.0.0/16". Docker picks one if empty.
+	Subnet string `json:"subnet,omitempty"`
+	// Gateway is the IPv4 gateway of the subnet.
+	Gateway string `json:"gateway,omitempty"`
+	// IPv6 enables IPv6 on the network, making it dual-stack.
+	IPv6 bool `json:"ipv6,omitempty"`
+	// IPv6Subnet is the IPv6 subnet of the network in CIDR notation, eg.
+	// "fd00:30::/64". Requires IPv6.
+	IPv6Subnet string `json:"ipv6Subnet,omitempty"`
+	// IPv6Gateway is the IPv6 gateway of the subnet.
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+	// Internal restricts external access to the network.
+	Internal bool `json:"internal,omitempty"`
+}
+
+// validate checks basic rules for Network's fields
+func (conf Network) validate() error {
+	if conf.Name == "" {
+		return errors.New("name is required")
+	}
+	if err := validateSubnet(conf.Subnet, conf.Gateway, false); err != nil {
+		return err
+	}
+	if !conf.IPv6 && (conf.IPv6Subnet != "" || conf.IPv6Gateway != "") {
+		return errors.New("ipv6Subnet and ipv6Gateway require ipv6 to be enabled")
+	}
+	if err := validateSubnet(conf.IPv6Subnet, conf.IPv6Gateway, true); err != nil {
+		return fmt.Errorf("ipv6 %w", err)
+	}
+	return nil
+}
+
+func validateSubnet(cidr, gateway string, ipv6 bool) error {
+	if gateway != "" && cidr == "" {
+		return errors.New("gateway requires a subnet")
+	}
+	if cidr == "" {
+		return nil
+	}
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil || (subnet.IP.To4() == nil) != ipv6 {
+		return fmt.Errorf("invalid subnet %q", cidr)
+	}
+	if gateway != "" {
+		ip := net.ParseIP(gateway)
+		if ip == nil || (ip.To4() == nil) != ipv6 {
+			return fmt.Errorf("invalid gateway %q", gateway)
+		}
+		if !subnet.Contains(ip) {
+			return fmt.Errorf("gateway %s is not in subnet %s", gateway, cidr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		network Network
+		err     string
+	}{
+		{"minimal", Network{Name: "net1"}, ""},
+		{"full", Network{Name: "net1", Driver: "bridge", Subnet: "172.30.0.0/16", Gateway: "172.30.0.1", IPv6: true, Internal: true}, ""},
+		{"no name", Network{Subnet: "172.30.0.0/16"}, "name is required"},
+		{"bad subnet", Network{Name: "net1", Subnet: "172.30.0.0"}, `invalid subnet "172.30.0.0"`},
+		{"bad gateway", Network{Name: "net1", Subnet: "172.30.0.0/16", Gateway: "gw"}, `invalid gateway "gw"`},
+		{"gateway outside subnet", Network{Name: "net1", Subnet: "172.30.0.0/16", Gateway: "172.31.0.1"}, "gateway 172.31.0.1 is not in subnet 172.30.0.0/16"},
+		{"gateway without subnet", Network{Name: "net1", Gateway: "172.30.0.1"}, "gateway requires a subnet"},
+		{"dual-stack", Network{Name: "net1", Subnet: "172.30.0.0/16", IPv6: true, IPv6Subnet: "fd00:30::/64", IPv6Gateway: "fd00:30::1"}, ""},
+		{"ipv6 subnet as subnet", Network{Name: "net1", Subnet: "fd00:30::/64"}, `invalid subnet "fd00:30::/64"`},
+		{"ipv4 subnet as ipv6 subnet", Network{Name: "net1", IPv6: true, IPv6Subnet: "172.30.0.0/16"}, `ipv6 invalid subnet "172.30.0.0/16"`},
+		{"ipv6 gateway outside subnet", Network{Name: "net1", IPv6: true, IPv6Subnet: "fd00:30::/64", IPv6Gateway: "fd00:31::1"}, "ipv6 gateway fd00:31::1 is not in subnet fd00:30::/64"},
+		{"ipv6 subnet without ipv6", Network{Name: "net1", IPv6Subnet: "fd00:30::/64"}, "require ipv6 to be enabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.network.validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfigValidateNetworks(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Cluster.Networks = []Network{{Name: "net1"}, {Name: "net1"}, {}}
+	err := conf.Validate()
+	assert.ErrorContains(t, err, `cluster.networks[1]: network "net1" is declared more than once`)
+	assert.ErrorContains(t, err, "cluster.networks[2]: name is required")
+}
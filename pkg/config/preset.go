@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of preset parameter values.
+const (
+	paramString = iota
+	paramCount
+	paramPort
+	paramList
+)
+
+// PresetParam is a knob used to build a Preset.
+type PresetParam struct {
+	// Name is the name of the parameter, as given on the command line.
+	Name string
+	// Description explains what the parameter controls.
+	Description string
+	// Default is the value used when the parameter is not given.
+	Default string
+	// kind of value, checked before building the preset.
+	kind int
+}
+
+// parse checks a parameter value and converts it to the parameter kind: a
+// string, an int, a uint16 or a []string.
+func (p PresetParam) parse(value string) (interface{}, error) {
+	switch p.kind {
+	case paramCount:
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("%s: %q is not a number of machines", p.Name, value)
+		}
+		return count, nil
+	case paramPort:
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a port number", p.Name, value)
+		}
+		return uint16(port), nil
+	case paramList:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("%s: the list is empty", p.Name)
+		}
+		return list, nil
+	default:
+		if value == "" {
+			return nil, fmt.Errorf("%s: a value is required", p.Name)
+		}
+		return value, nil
+	}
+}
+
+// presetValues are the parsed parameter values of a preset.
+type presetValues map[string]interface{}
+
+func (v presetValues) string(name string) string { return v[name].(string) }
+func (v presetValues) count(name string) int     { return v[name].(int) }
+func (v presetValues) port(name string) uint16   { return v[name].(uint16) }
+func (v presetValues) list(name string) []string { return v[name].([]string) }
+func (v presetValues) image() string             { return v.string(imageParam.Name) }
+func (v presetValues) replicas() int             { return v.count("replicas") }
+func (v presetValues) controllers() int          { return v.count(controllersParam.Name) }
+func (v presetValues) workers() int              { return v.count(workersParam.Name) }
+func (v presetValues) apiPort() uint16           { return v.port(apiPortParam.Name) }
+func (v presetValues) networks() []string        { return v.list("networks") }
+
+// Preset is a built-in configuration that can be used as a starting point for
+// new clusters, built from its parameters.
+type Preset struct {
+	// Name identifies the preset.
+	Name string
+	// Description is a one line summary of the preset.
+	Description string
+	// Params are the parameters accepted by the preset.
+	Params []PresetParam
+	// build returns the configuration for the parameter values.
+	build func(presetValues) Config
+}
+
+var (
+	imageParam = PresetParam{
+		Name:        "image",
+		Description: "Container image of the machines",
+		Default:     DefaultConfig().Machines[0].Spec.Image,
+	}
+	controllersParam = PresetParam{Name: "controllers", Description: "Number of controller machines", Default: "1", kind: paramCount}
+	workersParam     = PresetParam{Name: "workers", Description: "Number of worker machines", Default: "1", kind: paramCount}
+	apiPortParam     = PresetParam{Name: "apiPort", Description: "Host port of the Kubernetes API, 0 to pick a random port", Default: "0", kind: paramPort}
+)
+
+func replicasParam(defaultValue string) PresetParam {
+	return PresetParam{Name: "replicas", Description: "Number of machines", Default: defaultValue, kind: paramCount}
+}
+
+// presetConfig returns a configuration with the default cluster settings and
+// the given machines.
+func presetConfig(machines ...MachineReplicas) Config {
+	return Config{Cluster: DefaultConfig().Cluster, Machines: machines}
+}
+
+// presetMachine returns count machines named after name, reachable through
+// SSH.
+func presetMachine(name string, count int, image string) MachineReplicas {
+	return MachineReplicas{
+		Count: count,
+		Spec: &Machine{
+			Name:         name,
+			Image:        image,
+			PortMappings: []PortMapping{{ContainerPort: 22}},
+		},
+	}
+}
+
+// k0sMachine returns count privileged machines able to run k0s.
+func k0sMachine(name string, count int, image string) MachineReplicas {
+	machine := presetMachine(name, count, image)
+	machine.Spec.Privileged = true
+	machine.Spec.Volumes = []Volume{
+		{Type: "bind", Source: "/lib/modules", Destination: "/lib/modules", ReadOnly: true},
+		{Type: "volume", Destination: "/var/lib/k0s"},
+	}
+	return machine
+}
+
+var presets = map[string]Preset{
+	"default": {
+		Name:        "default",
+		Description: "A single machine template, the same as 'config create' without a preset",
+		Params:      []PresetParam{replicasParam("1"), imageParam},
+		build: func(v presetValues) Config {
+			return presetConfig(presetMachine("node%d", v.replicas(), v.image()))
+		},
+	},
+	"k0s": {
+		Name:        "k0s",
+		Description: "Controller and worker machines ready to run k0s",
+		Params:      []PresetParam{controllersParam, workersParam, apiPortParam, imageParam},
+		build: func(v presetValues) Config {
+			controller := k0sMachine("controller%d", v.controllers(), v.image())
+			controller.Spec.PortMappings = append(controller.Spec.PortMappings, PortMapping{ContainerPort: 6443, HostPort: v.apiPort()})
+			return presetConfig(controller, k0sMachine("worker%d", v.workers(), v.image()))
+		},
+	},
+	"k0s-lb-host": {
+		Name:        "k0s-lb-host",
+		Description: "k0s controllers and workers, and an empty machine publishing the API ports to install a load balancer on",
+		Params: []PresetParam{
+			{Name: "controllers", Description: "Number of controller machines", Default: "3", kind: paramCount},
+			{Name: "workers", Description: "Number of worker machines", Default: "2", kind: paramCount},
+			{Name: "apiPort", Description: "Host port of the Kubernetes API on the load balancer machine, 0 to pick a random port", Default: "6443", kind: paramPort},
+			imageParam,
+		},
+		build: func(v presetValues) Config {
+			lb := presetMachine("lb%d", 1, v.image())
+			lb.Spec.Labels = map[string]string{"role": "none"}
+			lb.Spec.PortMappings = append(lb.Spec.PortMappings,
+				PortMapping{ContainerPort: 6443, HostPort: v.apiPort()},
+				PortMapping{ContainerPort: 9443},
+				PortMapping{ContainerPort: 8132},
+			)
+			return presetConfig(lb, k0sMachine("controller%d", v.controllers(), v.image()), k0sMachine("worker%d", v.workers(), v.image()))
+		},
+	},
+	"dind": {
+		Name:        "dind",
+		Description: "Privileged machines able to run docker and containerd",
+		Params:      []PresetParam{replicasParam("1"), imageParam},
+		build: func(v presetValues) Config {
+			machine := presetMachine("node%d", v.replicas(), v.image())
+			machine.Spec.Privileged = true
+			machine.Spec.Volumes = []Volume{
+				{Type: "volume", Destination: "/var/lib/docker"},
+				{Type: "volume", Destination: "/var/lib/containerd"},
+			}
+			return presetConfig(machine)
+		},
+	},
+	"multi-network": {
+		Name:        "multi-network",
+		Description: "Machines attached to several user-defined networks",
+		Params: []PresetParam{
+			replicasParam("3"),
+			{Name: "networks", Description: "Comma separated list of networks, created along with the cluster", Default: "bootloose-net1,bootloose-net2", kind: paramList},
+			imageParam,
+		},
+		build: func(v presetValues) Config {
+			machine := presetMachine("node%d", v.replicas(), v.image())
+			machine.Spec.Networks = v.networks()
+			conf := presetConfig(machine)
+			for _, name := range v.networks() {
+				conf.Cluster.Networks = append(conf.Cluster.Networks, Network{Name: name})
+			}
+			return conf
+		},
+	},
+}
+
+// Presets returns the built-in presets sorted by name.
+func Presets() []Preset {
+	list := make([]Preset, 0, len(presets))
+	for _, preset := range presets {
+		list = append(list, preset)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// PresetByName returns the built-in preset with the given name.
+func PresetByName(name string) (Preset, error) {
+	preset, ok := presets[name]
+	if !ok {
+		names := make([]string, 0, len(presets))
+		for _, p := range Presets() {
+			names = append(names, p.Name)
+		}
+		return Preset{}, fmt.Errorf("unknown preset %q, available presets are: %s", name, strings.Join(names, ", "))
+	}
+	return preset, nil
+}
+
+// Config builds the preset with the given parameter values. Parameters not
+// present in values take their default value.
+func (p Preset) Config(values map[string]string) (Config, error) {
+	raw := map[string]string{}
+	for _, param := range p.Params {
+		raw[param.Name] = param.Default
+	}
+	for name, value := range values {
+		if _, ok := raw[name]; !ok {
+			return Config{}, fmt.Errorf("preset %s has no %q parameter", p.Name, name)
+		}
+		raw[name] = value
+	}
+	parsed := presetValues{}
+	for _, param := range p.Params {
+		value, err := param.parse(raw[param.Name])
+		if err != nil {
+			return Config{}, fmt.Errorf("preset %s: %w", p.Name, err)
+		}
+		parsed[param.Name] = value
+	}
+	return p.build(parsed), nil
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, preset := range Presets() {
+		t.Run(preset.Name, func(t *testing.T) {
+			conf, err := preset.Config(nil)
+			require.NoError(t, err)
+			assert.NoError(t, conf.Validate())
+			for _, machine := range conf.Machines {
+				assert.Equal(t, imageParam.Default, machine.Spec.Image)
+			}
+		})
+	}
+}
+
+func TestPresetDefaultMatchesDefaultConfig(t *testing.T) {
+	preset, err := PresetByName("default")
+	require.NoError(t, err)
+	conf, err := preset.Config(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), conf)
+}
+
+func TestPresetParams(t *testing.T) {
+	preset, err := PresetByName("k0s-lb-host")
+	require.NoError(t, err)
+	conf, err := preset.Config(map[string]string{"controllers": "5", "apiPort": "7443"})
+	require.NoError(t, err)
+	require.Len(t, conf.Machines, 3)
+	assert.Equal(t, "lb%d", conf.Machines[0].Spec.Name)
+	assert.Equal(t, uint16(7443), conf.Machines[0].Spec.PortMappings[1].HostPort)
+	assert.Equal(t, 5, conf.Machines[1].Count)
+	assert.Equal(t, 2, conf.Machines[2].Count)
+
+	preset, err = PresetByName("multi-network")
+	require.NoError(t, err)
+	conf, err = preset.Config(map[string]string{"networks": "a, b,c"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, conf.Machines[0].Spec.Networks)
+
+	_, err = preset.Config(map[string]string{"nope": "1"})
+	assert.ErrorContains(t, err, `preset multi-network has no "nope" parameter`)
+	_, err = preset.Config(map[string]string{"replicas": "many"})
+	assert.ErrorContains(t, err, `preset multi-network: replicas: "many" is not a number of machines`)
+	_, err = preset.Config(map[string]string{"networks": " , "})
+	assert.ErrorContains(t, err, "networks: the list is empty")
+
+	// Values are used as is, never parsed as configuration.
+	preset, err = PresetByName("k0s")
+	require.NoError(t, err)
+	conf, err = preset.Config(map[string]string{"image": "img\n  privileged: true"})
+	require.NoError(t, err)
+	assert.Equal(t, "img\n  privileged: true", conf.Machines[1].Spec.Image)
+	_, err = preset.Config(map[string]string{"controllers": "abc"})
+	assert.ErrorContains(t, err, `preset k0s: controllers: "abc" is not a number of machines`)
+	_, err = preset.Config(map[string]string{"apiPort": "70000"})
+	assert.ErrorContains(t, err, `preset k0s: apiPort: "70000" is not a port number`)
+
+	_, err = PresetByName("nope")
+	assert.ErrorContains(t, err, `unknown preset "nope", available presets are: default, dind, k0s, k0s-lb-host, multi-network`)
+}
@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// SetValueInYAML sets the value at the given string path of a YAML
+// serialization of a Config, using the same syntax as GetValueFromConfig.
+// Missing intermediate objects and map keys are created, and a slice element
+// can be appended by using the slice length as index. Scalar values are
+// converted to the type of the field, composite values are parsed as YAML.
+// Values with ${...} references are written as is, to be interpolated when the
+// configuration is loaded. The rest of the document, comments included, is
+// left untouched.
+func SetValueInYAML(data []byte, stringPath string, value string) ([]byte, error) {
+	return updateYAML(data, stringPath, true, func(container *yamlv3.Node, t reflect.Type, key string) error {
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(t, key)
+			if !ok {
+				return fmt.Errorf("%v key does not exist", key)
+			}
+			node, err := valueNode(value, field.Type)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			setMappingValue(container, jsonName(field), node)
+		case reflect.Slice:
+			index, err := sequenceIndex(container, key, true)
+			if err != nil {
+				return err
+			}
+			node, err := valueNode(value, t.Elem())
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			replaceNode(container.Content[index], node)
+		case reflect.Map:
+			node, err := valueNode(value, t.Elem())
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			setMappingValue(container, key, node)
+		}
+		return nil
+	})
+}
+
+// UnsetValueInYAML removes the value at the given string path of a YAML
+// serialization of a Config. Struct fields go back to their default value,
+// slice elements and map keys are removed.
+func UnsetValueInYAML(data []byte, stringPath string) ([]byte, error) {
+	return updateYAML(data, stringPath, false, func(container *yamlv3.Node, t reflect.Type, key string) error {
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(t, key)
+			if !ok {
+				return fmt.Errorf("%v key does not exist", key)
+			}
+			if i := mappingIndex(container, jsonName(field)); i >= 0 {
+				container.Content = append(container.Content[:i], container.Content[i+2:]...)
+			}
+		case reflect.Slice:
+			index, err := sequenceIndex(container, key, false)
+			if err != nil {
+				return err
+			}
+			container.Content = append(container.Content[:index], container.Content[index+1:]...)
+		case reflect.Map:
+			i := mappingIndex(container, key)
+			if i < 0 {
+				return fmt.Errorf("%v key does not exist", key)
+			}
+			container.Content = append(container.Content[:i], container.Content[i+2:]...)
+		}
+		return nil
+	})
+}
+
+func updateYAML(data []byte, stringPath string, create bool, update func(*yamlv3.Node, reflect.Type, string) error) ([]byte, error) {
+	segments, err := parsePath(stringPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("empty path")
+	}
+	if !isDefinite(segments) {
+		return nil, fmt.Errorf("%s: only keys and indexes can be used to update the config", stringPath)
+	}
+	keyPath := make([]string, 0, len(segments))
+	for _, s := range segments {
+		keyPath = append(keyPath, describe(s))
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		// Empty document.
+		doc = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+	}
+	if err := walkYAMLForUpdate(doc.Content[0], reflect.TypeOf(Config{}), keyPath, create, update); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	enc := yamlv3.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// walkYAMLForUpdate follows keyPath from node, of type t, and calls update
+// with the container node of the last key.
+func walkYAMLForUpdate(node *yamlv3.Node, t reflect.Type, keyPath []string, create bool, update func(*yamlv3.Node, reflect.Type, string) error) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	key := keyPath[0]
+	kind := yamlv3.MappingNode
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+	case reflect.Slice:
+		kind = yamlv3.SequenceNode
+	default:
+		return fmt.Errorf("%v is neither a slice, a map or a struct", t)
+	}
+	if isNullNode(node) {
+		// An empty value, eg. "spec:", is filled in.
+		if !create {
+			return fmt.Errorf("%v key does not exist", key)
+		}
+		*node = yamlv3.Node{Kind: kind, HeadComment: node.HeadComment, LineComment: node.LineComment}
+	}
+	if node.Kind != kind {
+		return fmt.Errorf("%v key: the config file has a %s where a %s is expected", key, nodeKindName(node.Kind), nodeKindName(kind))
+	}
+	if len(keyPath) == 1 {
+		return update(node, t, key)
+	}
+
+	var child *yamlv3.Node
+	var childType reflect.Type
+	switch t.Kind() {
+	case reflect.Struct:
+		field, ok := jsonField(t, key)
+		if !ok {
+			return fmt.Errorf("%v key does not exist", key)
+		}
+		key, childType = jsonName(field), field.Type
+	case reflect.Map:
+		childType = t.Elem()
+	default:
+		index, err := sequenceIndex(node, key, create)
+		if err != nil {
+			return err
+		}
+		return walkYAMLForUpdate(node.Content[index], t.Elem(), keyPath[1:], create, update)
+	}
+	if i := mappingIndex(node, key); i >= 0 {
+		child = node.Content[i+1]
+	} else if !create {
+		return fmt.Errorf("%v key does not exist", key)
+	} else {
+		child = &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null"}
+		setMappingValue(node, key, child)
+	}
+	return walkYAMLForUpdate(child, childType, keyPath[1:], create, update)
+}
+
+// mappingIndex returns the index of the key node of a mapping node, -1 when
+// missing. Like for struct fields, an exact match is preferred over a
+// case-insensitive one.
+func mappingIndex(node *yamlv3.Node, key string) int {
+	folded := -1
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch {
+		case node.Content[i].Value == key:
+			return i
+		case folded < 0 && strings.EqualFold(node.Content[i].Value, key):
+			folded = i
+		}
+	}
+	return folded
+}
+
+// setMappingValue replaces the value of key in a mapping node, or adds it.
+func setMappingValue(node *yamlv3.Node, key string, value *yamlv3.Node) {
+	if i := mappingIndex(node, key); i >= 0 {
+		replaceNode(node.Content[i+1], value)
+		return
+	}
+	node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// replaceNode replaces the content of a node, keeping its comments.
+func replaceNode(node, value *yamlv3.Node) {
+	head, line, foot := node.HeadComment, node.LineComment, node.FootComment
+	*node = *value
+	node.HeadComment, node.LineComment, node.FootComment = head, line, foot
+}
+
+// sequenceIndex parses key as an index into the sequence node. When grow is
+// true, the sequence length is accepted as well and a null element is
+// appended.
+func sequenceIndex(node *yamlv3.Node, key string, grow bool) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 {
+		return -1, fmt.Errorf("%v is not an index", key)
+	}
+	switch n := len(node.Content); {
+	case index < n:
+		return index, nil
+	case grow && index == n:
+		node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null"})
+		return index, nil
+	case grow:
+		return -1, fmt.Errorf("index %d is out of range, the next element to add is %d", index, n)
+	default:
+		return -1, fmt.Errorf("index %d is out of range", index)
+	}
+}
+
+func isNullNode(node *yamlv3.Node) bool {
+	return node.Kind == yamlv3.ScalarNode && (node.Tag == "!!null" || node.Tag == "" && node.Value == "")
+}
+
+func nodeKindName(kind yamlv3.Kind) string {
+	switch kind {
+	case yamlv3.MappingNode:
+		return "map"
+	case yamlv3.SequenceNode:
+		return "list"
+	default:
+		return "scalar"
+	}
+}
+
+// valueNode returns the YAML node of the textual representation s of a value
+// of type t.
+func valueNode(s string, t reflect.Type) (*yamlv3.Node, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+	default:
+		if strings.Contains(s, "${") {
+			return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: s}, nil
+		}
+	}
+	v, err := parseValue(s, t)
+	if err != nil {
+		return nil, err
+	}
+	// Marshal with the json field names before building the node.
+	data, err := yaml.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	node.Style &^= yamlv3.FlowStyle
+	return node, nil
+}
+
+// parseValue converts the textual representation s into a value of type t.
+func parseValue(s string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, fmt.Errorf("cannot use %q as %s", s, t.Kind())
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("cannot use %q as %s", s, t.Kind())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("cannot use %q as %s", s, t.Kind())
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("cannot use %q as %s", s, t.Kind())
+		}
+		v.SetFloat(f)
+	default:
+		if err := yaml.Unmarshal([]byte(s), v.Addr().Interface()); err != nil {
+			return v, fmt.Errorf("cannot use %q as %s: %w", s, t, err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const setTestConfig = `# CI cluster
+cluster:
+  name: cluster
+  privateKey: cluster-key
+machines:
+  - count: ${COUNT:-1} # bumped by CI
+    spec:
+      name: node%d
+      image: ${IMAGE}
+      portMappings:
+        - containerPort: 22
+          hostPort: ${SSH_PORT}
+`
+
+// setValues applies SetValueInYAML for each path and value pair.
+func setValues(t *testing.T, data string, pathValues ...string) string {
+	t.Helper()
+	for i := 0; i < len(pathValues); i += 2 {
+		out, err := SetValueInYAML([]byte(data), pathValues[i], pathValues[i+1])
+		require.NoError(t, err, pathValues[i])
+		data = string(out)
+	}
+	return data
+}
+
+func TestSetValueInYAML(t *testing.T) {
+	data := setValues(t, setTestConfig,
+		"cluster.name", "ci",
+		"machines[0].spec.privileged", "true",
+		"machines[0].spec.networks", "[net1, net2]",
+		"machines[0].spec.networks[2]", "net3",
+		// A new template is created from scratch, including its spec.
+		"machines[1].spec.name", "worker%d",
+		"machines[1].spec.image", "quay.io/k0sproject/bootloose-debian13",
+		"machines[1].count", "2",
+	)
+	assert.Equal(t, `# CI cluster
+cluster:
+  name: ci
+  privateKey: cluster-key
+machines:
+  - count: ${COUNT:-1} # bumped by CI
+    spec:
+      name: node%d
+      image: ${IMAGE}
+      portMappings:
+        - containerPort: 22
+          hostPort: ${SSH_PORT}
+      privileged: true
+      networks:
+        - net1
+        - net2
+        - net3
+  - spec:
+      name: worker%d
+      image: quay.io/k0sproject/bootloose-debian13
+    count: 2
+`, data)
+
+	conf, err := NewConfigFromYAML([]byte(data), WithLookupEnv(func(name string) (string, bool) {
+		return map[string]string{"IMAGE": "quay.io/k0sproject/bootloose-ubuntu22.04", "SSH_PORT": "2222"}[name], true
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, conf.Machines[0].Count)
+	assert.Equal(t, uint16(2222), conf.Machines[0].Spec.PortMappings[0].HostPort)
+	assert.Equal(t, 2, conf.Machines[1].Count)
+	assert.NoError(t, conf.Validate())
+}
+
+func TestSetValueInYAMLTypes(t *testing.T) {
+	data := setValues(t, setTestConfig,
+		// Scalars are typed after the field, references are kept as strings.
+		"machines[0].spec.portMappings[0].hostPort", "2223",
+		"machines[0].count", "${COUNT:-3}",
+		"machines[0].spec.env.DEBUG", "1",
+	)
+	assert.Contains(t, data, "          hostPort: 2223\n")
+	assert.Contains(t, data, "  - count: ${COUNT:-3} # bumped by CI\n")
+	assert.Contains(t, data, "      env:\n        DEBUG: \"1\"\n")
+}
+
+func TestSetValueInYAMLErrors(t *testing.T) {
+	set := func(path, value string) error {
+		_, err := SetValueInYAML([]byte(setTestConfig), path, value)
+		return err
+	}
+	assert.ErrorContains(t, set("machines[0].count", "three"), `count: cannot use "three" as int`)
+	assert.ErrorContains(t, set("machines[0].spec.portMappings[0].hostPort", "70000"), `cannot use "70000" as uint16`)
+	assert.ErrorContains(t, set("machines[5].count", "1"), "index 5 is out of range, the next element to add is 1")
+	assert.ErrorContains(t, set("cluster.nope", "1"), "nope key does not exist")
+	assert.ErrorContains(t, set("cluster.name.first", "1"), "is neither a slice, a map or a struct")
+	assert.ErrorContains(t, set("machines[*].count", "1"), "only keys and indexes can be used")
+}
+
+func TestUnsetValueInYAML(t *testing.T) {
+	data := setValues(t, setTestConfig, "machines[0].spec.networks", "[net1, net2, net3]")
+
+	out, err := UnsetValueInYAML([]byte(data), "machines[0].spec.networks[1]")
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "      networks:\n        - net1\n        - net3\n")
+
+	out, err = UnsetValueInYAML(out, "cluster.privateKey")
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "privateKey")
+
+	// Unset fields are already at their default value.
+	_, err = UnsetValueInYAML(out, "cluster.domain")
+	assert.NoError(t, err)
+
+	_, err = UnsetValueInYAML(out, "machines[3]")
+	assert.ErrorContains(t, err, "index 3 is out of range")
+	_, err = UnsetValueInYAML(out, "machines[0].spec.env.DEBUG")
+	assert.ErrorContains(t, err, "env key does not exist")
+
+	out, err = UnsetValueInYAML(out, "machines[0]")
+	require.NoError(t, err)
+	assert.Equal(t, "# CI cluster\ncluster:\n  name: cluster\nmachines: []\n", string(out))
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// User is a user account created on the machines along with root.
+type User struct {
+	// Name is the login name of the user.
+	Name string `json:"name"`
+	// UID is the user ID. The system picks one if 0.
+	UID int `json:"uid,omitempty"`
+	// Groups are supplementary groups of the user, created if missing.
+	Groups []string `json:"groups,omitempty"`
+	// Shell is the login shell of the user. Defaults to the system default.
+	Shell string `json:"shell,omitempty"`
+	// Sudo allows the user to run any command as root with sudo, without
+	// password. The machine image has to provide sudo.
+	Sudo bool `json:"sudo,omitempty"`
+	// AuthorizedKeys are the public keys allowed to log in as the user.
+	// Defaults to the public key authorized for root.
+	AuthorizedKeys []string `json:"authorizedKeys,omitempty"`
+}
+
+var userName = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// validate checks basic rules for User's fields
+func (conf User) validate() error {
+	if !userName.MatchString(conf.Name) {
+		return fmt.Errorf("user name %q is not valid", conf.Name)
+	}
+	if conf.Name == "root" {
+		return errors.New("root cannot be declared as a user")
+	}
+	if conf.UID < 0 {
+		return fmt.Errorf("%s: uid cannot be negative", conf.Name)
+	}
+	for _, group := range conf.Groups {
+		if !userName.MatchString(group) {
+			return fmt.Errorf("%s: group name %q is not valid", conf.Name, group)
+		}
+	}
+	if conf.Shell != "" && (!strings.HasPrefix(conf.Shell, "/") || strings.ContainsAny(conf.Shell, " \t\n'")) {
+		return fmt.Errorf("%s: shell %q is not an absolute path", conf.Name, conf.Shell)
+	}
+	for i, key := range conf.AuthorizedKeys {
+		if strings.TrimSpace(key) == "" || strings.Contains(key, "\n") {
+			return fmt.Errorf("%s: authorizedKeys[%d] should be a single public key line", conf.Name, i)
+		}
+	}
+	return nil
+}
+
+// validateUsers checks a list of users, whose names have to be unique.
+func validateUsers(users []User) error {
+	names := map[string]bool{}
+	for i, user := range users {
+		if err := user.validate(); err != nil {
+			return fmt.Errorf("users[%d]: %w", i, err)
+		}
+		if names[user.Name] {
+			return fmt.Errorf("users[%d]: user %s is declared more than once", i, user.Name)
+		}
+		names[user.Name] = true
+	}
+	return nil
+}
@@ -19,6 +19,11 @@ limitations under the License.
 package docker
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/k0sproject/bootloose/pkg/exec"
 )
 
@@ -41,3 +46,26 @@ func CopyFrom(containerNameOrID, srcPath, hostPath string) error {
 	)
 	return cmd.Run()
 }
+
+// Copy copies files between the host and a container, src and dest being host
+// paths or container:path. A "-" src reads a tar archive from stdin, a "-" dest
+// writes one to stdout. With archive, copied files keep their owner instead of
+// belonging to the container root.
+func Copy(src, dest string, archive bool, stdin io.Reader, stdout io.Writer) error {
+	args := []string{"cp"}
+	if archive {
+		args = append(args, "--archive")
+	}
+	cmd := exec.Command("docker", append(args, src, dest)...)
+	var stderr bytes.Buffer
+	cmd.SetStdin(stdin)
+	cmd.SetStdout(stdout)
+	cmd.SetStderr(&stderr)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -20,6 +20,9 @@ package docker
 
 import (
 	"io"
+	"os"
+
+	"golang.org/x/term"
 
 	"github.com/k0sproject/bootloose/pkg/exec"
 )
@@ -68,9 +71,9 @@ func (c *containerCmd) Run() error {
 			"-i", // interactive so we can supply input
 		)
 	}
-	if c.stderr != nil || c.stdout != nil {
+	if isTerminal(c.stdout) {
 		args = append(args,
-			"-t", // use a tty so we can get output
+			"-t", // use a tty when attached to one, keeping stderr apart otherwise
 		)
 	}
 	// set env
@@ -102,6 +105,12 @@ func (c *containerCmd) Run() error {
 	return cmd.Run()
 }
 
+// isTerminal returns true when w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
 func (c *containerCmd) SetEnv(env ...string) {
 	c.env = env
 }
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 bootloose authors
+// SPDX-License-Identifier: Apache-2.0
+
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k0sproject/bootloose/pkg/exec"
+)
+
+// CreateNetwork creates a network, passing args to docker network create.
+func CreateNetwork(network string, args []string) error {
+	cmd := exec.Command("docker", append(append([]string{"network", "create"}, args...), network)...)
+	return runWithLogging(cmd)
+}
+
+// RemoveNetwork removes a network.
+func RemoveNetwork(network string) error {
+	cmd := exec.Command("docker", "network", "rm", network)
+	return runWithLogging(cmd)
+}
+
+// NetworkExists checks whether a network with the given name exists.
+func NetworkExists(network string) (bool, error) {
+	cmd := exec.Command("docker", "network", "ls", "--format", "{{.Name}}")
+	lines, err := exec.CombinedOutputLines(cmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to list networks: %w", err)
+	}
+	for _, line := range lines {
+		if line == network {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InspectNetwork returns low-level information on a network.
+func InspectNetwork(network, format string) ([]string, error) {
+	cmd := exec.Command("docker", "network", "inspect", "-f", format, network)
+	lines, err := exec.CombinedOutputLines(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect network %s: %w: %s", network, err, strings.Join(lines, "\n"))
+	}
+	return lines, nil
+}
@@ -34,3 +34,16 @@ func ConnectNetworkWithAlias(container, network, alias string) error {
 	cmd := exec.Command("docker", "network", "connect", network, container, "--alias", alias)
 	return runWithLogging(cmd)
 }
+
+// ConnectNetworkWithIP connects network to container with a static IPv4
+// address, adding a network-scoped alias for the container.
+func ConnectNetworkWithIP(container, network, alias, ip string) error {
+	cmd := exec.Command("docker", "network", "connect", network, container, "--alias", alias, "--ip", ip)
+	return runWithLogging(cmd)
+}
+
+// DisconnectNetwork disconnects container from network.
+func DisconnectNetwork(container, network string) error {
+	cmd := exec.Command("docker", "network", "disconnect", network, container)
+	return runWithLogging(cmd)
+}
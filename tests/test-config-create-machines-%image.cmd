@@ -0,0 +1,15 @@
+# SPDX-FileCopyrightText: 2026 bootloose authors
+# SPDX-License-Identifier: Apache-2.0
+# Checks that `bootloose config create` can describe several machine templates
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --machine name=controller%d,count=3,port=6443:6443,network=net1 --machine name=worker%d,count=2,privileged=true --port 127.0.0.1:8080:80/tcp --env K0S_VERSION=v1.30.0 --extra-arg --cap-add=NET_ADMIN
+%defer rm -f %testName.bootloose %testName-key %testName-key.pub
+
+%out bootloose config get --config %testName.bootloose -o json machines
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --preset k0s --param controllers=3 --machine name=worker%d,count=1
+%out bootloose config get --config %testName.bootloose -o raw machines[*].count
+
+%error bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --preset k0s --replicas 2
+%error bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --machine name=node%d,network=net1 --networks net2
+%error bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --preset k0s --from-template dind
@@ -0,0 +1,15 @@
+# SPDX-FileCopyrightText: 2026 bootloose authors
+# SPDX-License-Identifier: Apache-2.0
+# Checks `bootloose config get` queries and output formats
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --networks=net1,net2 --image %image
+%defer rm -f %testName.bootloose %testName-key %testName-key.pub
+
+bootloose config set --config %testName.bootloose machines[1].spec.name worker%d
+bootloose config set --config %testName.bootloose machines[1].spec.image %image
+bootloose config set --config %testName.bootloose machines[1].count 2
+
+%out bootloose config get --config %testName.bootloose -o raw machines[*].spec.name
+%out bootloose config get --config %testName.bootloose -o raw machines[0].spec.networks
+%out bootloose config get --config %testName.bootloose -o yaml machines[?(@.count>1)].spec.name
+%out bootloose config get --config %testName.bootloose -o json machines[-1].count
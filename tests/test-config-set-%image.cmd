@@ -0,0 +1,17 @@
+# SPDX-FileCopyrightText: 2026 bootloose authors
+# SPDX-License-Identifier: Apache-2.0
+# Checks that `bootloose config set` and `bootloose config unset` update the config file
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --networks=net1,net2 --image %image
+%defer rm -f %testName.bootloose %testName-key %testName-key.pub
+
+bootloose config set --config %testName.bootloose machines[0].spec.portMappings[0].hostPort 2222
+bootloose config set --config %testName.bootloose machines[1].spec.name worker%d
+bootloose config set --config %testName.bootloose machines[1].spec.image %image
+bootloose config set --config %testName.bootloose machines[1].count 2
+bootloose config unset --config %testName.bootloose machines[0].spec.networks[0]
+
+%out bootloose config get --config %testName.bootloose machines
+
+# invalid results are not saved
+%error bootloose config set --config %testName.bootloose machines[1].spec.name worker
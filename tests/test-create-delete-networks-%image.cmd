@@ -0,0 +1,14 @@
+# SPDX-FileCopyrightText: 2026 bootloose authors
+# SPDX-License-Identifier: Apache-2.0
+# Checks that networks declared in the config are created and deleted with the cluster
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --networks %testName-net
+%defer rm -f %testName.bootloose %testName-key %testName-key.pub
+bootloose config set --config %testName.bootloose cluster.networks[0].name %testName-net
+bootloose config set --config %testName.bootloose cluster.networks[0].subnet 172.30.0.0/16
+%defer bootloose delete --config %testName.bootloose
+bootloose create --config %testName.bootloose
+%out docker network ls --format {{.Name}} -f label=io.k0sproject.bootloose.cluster=%testName
+%out docker network inspect -f {{range.IPAM.Config}}{{.Subnet}}{{end}} %testName-net
+bootloose delete --config %testName.bootloose
+%out docker network ls --format {{.Name}} -f label=io.k0sproject.bootloose.cluster=%testName
@@ -0,0 +1,13 @@
+# SPDX-FileCopyrightText: 2026 bootloose authors
+# SPDX-License-Identifier: Apache-2.0
+# Checks that machines get the static addresses from the config
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --networks %testName-net --replicas 2
+%defer rm -f %testName.bootloose %testName-key %testName-key.pub
+bootloose config set --config %testName.bootloose cluster.networks[0].name %testName-net
+bootloose config set --config %testName.bootloose cluster.networks[0].subnet 172.30.0.0/16
+bootloose config set --config %testName.bootloose machines[0].spec.addresses[0].network %testName-net
+bootloose config set --config %testName.bootloose machines[0].spec.addresses[0].ipv4Start 172.30.0.10
+%defer bootloose delete --config %testName.bootloose
+bootloose create --config %testName.bootloose
+%out docker inspect -f {{range.NetworkSettings.Networks}}{{.IPAddress}}{{end}} %testName-node0 %testName-node1
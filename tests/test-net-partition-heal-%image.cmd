@@ -0,0 +1,12 @@
+# SPDX-FileCopyrightText: 2026 bootloose authors
+# SPDX-License-Identifier: Apache-2.0
+# Checks that `bootloose net partition` disconnects machines and `bootloose net heal` reconnects them
+
+bootloose config create --override --config %testName.bootloose --name %testName --key %testName-key --image %image --replicas 2
+%defer rm -f %testName.bootloose %testName-key %testName-key.pub
+%defer bootloose delete --config %testName.bootloose
+bootloose create --config %testName.bootloose
+bootloose net partition --config %testName.bootloose node0
+%out docker inspect -f {{range$name,$_:=.NetworkSettings.Networks}}{{$name}}{{end}} %testName-node0 %testName-node1
+bootloose net heal --config %testName.bootloose
+%out docker inspect -f {{range$name,$_:=.NetworkSettings.Networks}}{{$name}}{{end}} %testName-node0 %testName-node1